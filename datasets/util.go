@@ -1,7 +1,9 @@
 package datasets
 
 import (
+	"errors"
 	"math/rand"
+	"sort"
 	"time"
 )
 
@@ -13,4 +15,31 @@ func RandomShuffle(X [][]float64, y []float64) {
 		X[i], X[newIndex] = X[newIndex], X[i]
 		y[i], y[newIndex] = y[newIndex], y[i]
 	}
+}
+
+
+// checks that there are samples (X) and one response (y) per sample
+func CheckInput(X [][]float64, y []float64) error {
+	if len(X) == 0 {
+		return errors.New("no samples to fit")
+	}
+	if len(X) != len(y) {
+		return errors.New("number of samples and responses differ")
+	}
+	return nil
+}
+
+
+// sorted distinct labels in y
+func Classes(y []float64) []float64 {
+	seen := make(map[float64]bool)
+	var classes []float64
+	for _, v := range y {
+		if !seen[v] {
+			seen[v] = true
+			classes = append(classes, v)
+		}
+	}
+	sort.Float64s(classes)
+	return classes
 }
\ No newline at end of file
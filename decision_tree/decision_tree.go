@@ -4,22 +4,32 @@ import (
 	"sync"
 	"fmt"
 	"errors"
+	"math"
+	"sort"
+	"container/heap"
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
 )
 
 const GINI = "gini"
 
-type splitFunction func([][]float64, []float64, int) (float64, float64)
+// splits column index of (X, y) keeping minLeaf samples on each side; y
+// holds class indices in {0, ..., nClasses-1}, or responses for regression
+type splitFunction func(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64)
 
 type decisionTree struct {
-	root    *treeNode     // actual tree
-	context *treeContext  // fitting context
+	root      *treeNode     // actual tree
+	context   *treeContext  // fitting context
+	classes   []float64     // sorted distinct labels seen by Fit
+	smoothing float64       // pseudo-count added to every class by PredictProba
 }
 
 type treeContext struct {
-	splitter splitFunction  // what impurity critera to split by
-	curDepth int            // how deep are we
-	maxDepth int            // maximum tree depth
-	used     []int          // which columns have we used?
+	splitter  splitFunction             // what impurity critera to split by
+	options   TreeOptions               // limits on how the tree grows
+	nSamples  int                       // number of training samples
+	nClasses  int                       // number of distinct labels
+	leafValue func([]float64) float64   // regression leaf prediction, nil when classifying
 }
 
 type treeNode struct {
@@ -28,7 +38,8 @@ type treeNode struct {
 	impurity    float64    // impurity value
 	splitColumn int        // index of column to split by
 	splitVal    float64    // value to split on
-	probability float64    // P(1|t)
+	counts      []float64  // number of samples of each class
+	value       float64    // mean or median response (regression trees)
 	size        int        // number of samples in this sub tree
 }
 
@@ -36,31 +47,147 @@ type splitResult struct {
 	impurity    float64  // impurity value from split criteria
 	splitColumn int      // best column to split on
 	splitVal    float64  // value to split on
+	parent      float64  // impurity of the node before splitting
 }
 
+// controls how far a tree grows; see NewTreeOptions for the defaults
+type TreeOptions struct {
+	MaxDepth            int      // max depth of constructed tree
+	MinSamplesSplit     int      // fewest samples a node needs to be split
+	MinSamplesLeaf      int      // fewest samples each side of a split must keep
+	MinImpurityDecrease float64  // smallest impurity decrease, weighted by the
+	                             // node's share of the samples, a split must make
+	MaxLeafNodes        int      // grow best first up to this many leaves, 0 for no limit
+	ReuseFeatures       bool     // whether a column may be split again deeper down
+}
+
+
+/*
+ options limiting only the depth of the tree, as used by DecisionTree
+ and RegressionTree: any node with two samples may be split, and each
+ column is split at most once on the way from the root to a leaf
+*/
+func NewTreeOptions(maxDepth int) TreeOptions {
+	return TreeOptions{
+		MaxDepth: maxDepth,
+		MinSamplesSplit: 2,
+		MinSamplesLeaf: 1,
+	}
+}
+
+
+func checkOptions(options TreeOptions) error {
+	if options.MaxDepth < 0 {
+		return errors.New("max depth must be non-negative")
+	}
+	if options.MinSamplesSplit < 2 {
+		return errors.New("min samples to split must be at least 2")
+	}
+	if options.MinSamplesLeaf < 1 {
+		return errors.New("min samples per leaf must be at least 1")
+	}
+	if options.MinImpurityDecrease < 0 {
+		return errors.New("min impurity decrease must be non-negative")
+	}
+	if options.MaxLeafNodes != 0 && options.MaxLeafNodes < 2 {
+		return errors.New("max leaf nodes must be 0 (no limit) or at least 2")
+	}
+	return nil
+}
+
+
 /*
  decision tree constructor
 
  arguments
  ---------
    maxDepth:       max depth of constructed tree
-   minSamplesLeaf: necessary samples needed to construct a leaf node
    splitMethod:    what criteria to use to calculate impurity
-                   possible values: GINI ("gini")
+                   possible values: GINI ("gini"), ENTROPY ("entropy"),
+                   GAIN_RATIO ("gain_ratio"), LOG_LOSS ("log_loss"), or
+                   any name added with RegisterImpurity
 */
 func DecisionTree(maxDepth int, splitMethod string) (*decisionTree, error) {
-	var splitter splitFunction
+	return DecisionTreeWithOptions(splitMethod, NewTreeOptions(maxDepth))
+}
 
-	if splitMethod == "gini" {
-		splitter = splitGINI
-	} else {
+
+/*
+ decision tree constructor
+
+ arguments
+ ---------
+   splitMethod: what criteria to use to calculate impurity, as for
+                DecisionTree
+   options:     limits on how the tree grows
+*/
+func DecisionTreeWithOptions(splitMethod string, options TreeOptions) (*decisionTree, error) {
+	splitter, ok := lookupSplitter(splitMethod)
+	if !ok {
+		return nil, errors.New("unknown splitting method")
+	}
+	if err := checkOptions(options); err != nil {
+		return nil, err
+	}
+
+	tree := new(decisionTree)
+	tree.context = new(treeContext)
+	tree.context.splitter = splitter
+	tree.context.options = options
+
+	return tree, nil
+}
+
+
+/*
+ regression tree constructor
+
+ arguments
+ ---------
+   maxDepth:    max depth of constructed tree
+   splitMethod: what criteria to use to calculate impurity
+                possible values: MSE ("mse"), leaves predict the mean
+                                 MAE ("mae"), leaves predict the median
+                                 FRIEDMAN_MSE ("friedman_mse"), leaves
+                                   predict the mean
+*/
+func RegressionTree(maxDepth int, splitMethod string) (*decisionTree, error) {
+	return RegressionTreeWithOptions(splitMethod, NewTreeOptions(maxDepth))
+}
+
+
+/*
+ regression tree constructor
+
+ arguments
+ ---------
+   splitMethod: what criteria to use to calculate impurity, as for
+                RegressionTree
+   options:     limits on how the tree grows
+*/
+func RegressionTreeWithOptions(splitMethod string, options TreeOptions) (*decisionTree, error) {
+	var splitter splitFunction
+	var leafValue func([]float64) float64
+
+	switch splitMethod {
+	case MSE:
+		splitter, leafValue = splitMSE, mean
+	case MAE:
+		splitter, leafValue = splitMAE, median
+	case FRIEDMAN_MSE:
+		splitter, leafValue = splitFriedmanMSE, mean
+	default:
 		return nil, errors.New("unknown splitting method")
 	}
+	if err := checkOptions(options); err != nil {
+		return nil, err
+	}
 
 	tree := new(decisionTree)
 	tree.context = new(treeContext)
 	tree.context.splitter = splitter
-	tree.context.maxDepth = maxDepth
+	tree.context.options = options
+	tree.context.leafValue = leafValue
 
 	return tree, nil
 }
@@ -70,10 +197,31 @@ func (tree decisionTree) String() string {
 }
 
 
-// fit this decision tree with samples (X) and responses (y)
+// fit this decision tree with samples (X) and class labels (y), which may be any
+// floats, or continuous responses (y) for a regression tree
 func (tree *decisionTree) Fit(X [][]float64, y []float64) error {
-	tree.context.used = make([]int, len(X))
-	tree.root = fitTree(X, y, tree.context)
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	// classifiers fit on class indices 0..K-1; the dataset is split in
+	// place, so work on copies rather than reordering the caller's X and y
+	yIndex := make([]float64, len(y))
+	if tree.context.leafValue != nil {
+		tree.classes = nil
+		copy(yIndex, y)
+	} else {
+		tree.classes = datasets.Classes(y)
+		for i, label := range y {
+			yIndex[i] = float64(sort.SearchFloat64s(tree.classes, label))
+		}
+	}
+	rows := make([][]float64, len(X))
+	copy(rows, X)
+
+	tree.context.nSamples = len(X)
+	tree.context.nClasses = len(tree.classes)
+	tree.root = fitTree(rows, yIndex, tree.context)
 	return nil
 }
 
@@ -88,29 +236,105 @@ func (tree decisionTree) Classify(X [][]float64) []float64 {
 }
 
 
-// classify single sample, return predicted label
+// classify single sample, return predicted label (the leaf's majority class)
 func (tree decisionTree) ClassifySample(x []float64) float64 {
-	var label float64
-	node := tree.root
+	return tree.PredictSample(x)
+}
 
-	for {
-		if node.isLeaf() {
-			if node.probability > 0.5 {
-				label = 1
-			} else {
-				label = 0
-			}
-			break
+
+// predict samples (X), return predicted labels or responses
+func (tree decisionTree) Predict(X [][]float64) []float64 {
+	y := make([]float64, len(X))
+	for i := range y {
+		y[i] = tree.PredictSample(X[i])
+	}
+	return y
+}
+
+
+// predict single sample: the leaf's majority class, or for regression
+// trees the leaf's mean or median response
+func (tree decisionTree) PredictSample(x []float64) float64 {
+	node := tree.leaf(x)
+	if tree.context.leafValue != nil {
+		return node.value
+	}
+	return tree.classes[matrix.VecArgMax(node.counts)]
+}
+
+
+/*
+ predict the probability of each class (ordered as Classes) for samples
+ (X): the class proportions of the leaf each sample falls into. With
+ smoothing alpha (see SetSmoothing) the probabilities are (n_k + alpha) / (n + alpha K), so
+ alpha = 1 (Laplace smoothing) keeps small leaves from being certain.
+
+ returns
+ -------
+   one row of probabilities per sample, nil for regression trees
+*/
+func (tree decisionTree) PredictProba(X [][]float64) [][]float64 {
+	if tree.context.leafValue != nil {
+		return nil
+	}
+
+	proba := make([][]float64, len(X))
+	for i := range X {
+		proba[i] = tree.PredictProbaSample(X[i])
+	}
+	return proba
+}
+
+
+// probability of each class (ordered as Classes) for single sample
+func (tree decisionTree) PredictProbaSample(x []float64) []float64 {
+	node := tree.leaf(x)
+	total := float64(node.size) + tree.smoothing * float64(len(node.counts))
+
+	p := make([]float64, len(node.counts))
+	for k, n := range node.counts {
+		p[k] = (n + tree.smoothing) / total
+	}
+	return p
+}
+
+
+/*
+ set the pseudo-count PredictProba adds to every class, 0 (the default)
+ for the raw leaf proportions
+
+ returns
+ -------
+   error if alpha is negative, which would make probabilities negative or
+   greater than one
+*/
+func (tree *decisionTree) SetSmoothing(alpha float64) error {
+	if alpha < 0 || math.IsNaN(alpha) {
+		return errors.New("smoothing must be non-negative")
+	}
+	tree.smoothing = alpha
+	return nil
+}
+
+
+// sorted distinct labels seen by Fit, nil for regression trees
+func (tree decisionTree) Classes() []float64 {
+	return tree.classes
+}
+
+
+// leaf node that sample (x) falls into
+func (tree decisionTree) leaf(x []float64) *treeNode {
+	node := tree.root
+	for !node.isLeaf() {
+		i, val := node.splitColumn, node.splitVal
+		if x[i] < val {
+			node = node.left
 		} else {
-			i, val := node.splitColumn, node.splitVal
-			if x[i] < val {
-				node = node.left
-			} else {
-				node = node.right
-			}
+			node = node.right
 		}
 	}
-	return label
+	return node
 }
 
 
@@ -123,8 +347,10 @@ func (n treeNode) String() string {
 	var toString func(*treeNode, string) string
 	toString = func(n *treeNode, padding string) string {
 		var s string
-		if n.isLeaf() {
-			s = fmt.Sprintf("%s(%.3f +%d)", padding, n.probability, n.size)
+		if n.isLeaf() && n.counts == nil {
+			s = fmt.Sprintf("%s(%.3f +%d)", padding, n.value, n.size)
+		} else if n.isLeaf() {
+			s = fmt.Sprintf("%s(%v +%d)", padding, n.counts, n.size)
 		} else {
 			s = fmt.Sprintf("%s%d < %.2f  (%.3f +%d)",
 				padding,
@@ -147,70 +373,150 @@ func (n treeNode) String() string {
 
 
 /*
- fits a decision tree
+ fits a decision tree. Nodes that can be split wait in a queue ordered
+ by the impurity decrease of their best split; without a limit on the
+ number of leaves every one of them is split, in any order, while with
+ MaxLeafNodes the most useful splits are made first.
 
  arguments
  ---------
    X:        training samples
-   y:        corresponding responses; should be in {0, 1}
-   context:  training context: how deep we are, stopping cases, etc.
+   y:        corresponding class indices in {0, ..., nClasses-1}, or
+             responses for a regression tree
+   context:  training context: stopping cases, etc.
 
  returns
  -------
    tree root node
 */
 func fitTree(X [][]float64, y []float64, context *treeContext) *treeNode {
+	root := newNode(y, context)
+	queue := new(candidateQueue)
+	if c := findSplit(root, X, y, 0, make([]int, len(X[0])), context); c != nil {
+		heap.Push(queue, c)
+	}
+
+	leaves := 1
+	maxLeaves := context.options.MaxLeafNodes
+	for queue.Len() > 0 && (maxLeaves == 0 || leaves < maxLeaves) {
+		c := heap.Pop(queue).(*splitCandidate)
+
+		// populate the node's splitting point
+		node := c.node
+		node.impurity = c.split.impurity
+		node.splitColumn = c.split.splitColumn
+		node.splitVal = c.split.splitVal
+
+		// what index should we split the dataset by?
+		ix := splitDataset(c.X, c.y, node.splitColumn, node.splitVal)
+
+		// unless reusing features, sub trees can no longer use this column
+		used := c.used
+		if !context.options.ReuseFeatures {
+			used = copySlice(used)
+			used[node.splitColumn] = 1
+		}
+
+		node.left = newNode(c.y[:ix], context)
+		node.right = newNode(c.y[ix:], context)
+		leaves++
+
+		if l := findSplit(node.left, c.X[:ix], c.y[:ix], c.depth + 1, used, context); l != nil {
+			heap.Push(queue, l)
+		}
+		if r := findSplit(node.right, c.X[ix:], c.y[ix:], c.depth + 1, used, context); r != nil {
+			heap.Push(queue, r)
+		}
+	}
+
+	return root
+}
+
+
+// leaf node for responses (y): its class counts, or its prediction for regression
+func newNode(y []float64, context *treeContext) *treeNode {
 	node := new(treeNode)
+	node.size = len(y)
+
+	if context.leafValue != nil {
+		node.value = context.leafValue(y)
+	} else {
+		// count the samples of each class
+		node.counts = make([]float64, context.nClasses)
+		for _, v := range y { node.counts[int(v)]++ }
+	}
 
-	// calculate node's probability
-	labelSum := 0.0
-	for _, v := range y { labelSum += v }
-	node.probability = labelSum / float64(len(y))
-	node.size = len(X)
+	return node
+}
 
-	// should we split this tree further?
-	// 1) must not exceed maxDepth
-	// 2) must have both positive and negative samples
-	should_split := context.curDepth < context.maxDepth &&
-		node.probability != 0 &&
-		node.probability != 1
 
-	if should_split {
-		// find best splitting column we haven't used
-		result := bestSplit(X, y, context)
+// node waiting to be split, with the part of the dataset that reaches it
+type splitCandidate struct {
+	node     *treeNode
+	X        [][]float64
+	y        []float64
+	depth    int          // depth of node
+	used     []int        // which columns have we used?
+	split    splitResult  // best split of node
+	decrease float64      // weighted impurity decrease of the split
+}
+
+// max-heap of split candidates by impurity decrease
+type candidateQueue []*splitCandidate
+
+func (q candidateQueue) Len() int { return len(q) }
+func (q candidateQueue) Less(i, j int) bool { return q[i].decrease > q[j].decrease }
+func (q candidateQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *candidateQueue) Push(x interface{}) { *q = append(*q, x.(*splitCandidate)) }
+func (q *candidateQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old) - 1]
+	*q = old[:len(old) - 1]
+	return c
+}
 
-		// did we successfully split?
-		couldSplit := result.splitColumn != -1
 
-		if couldSplit {
-			// this tree's depth, sub trees will have +1
-			myDepth := context.curDepth
+/*
+ finds the best split of node given the samples (X) and responses (y)
+ reaching it
 
-			// populate the new node's splitting point
-			node.impurity = result.impurity
-			node.splitColumn = result.splitColumn
-			node.splitVal = result.splitVal
+ returns
+ -------
+   split candidate, or nil if the node should stay a leaf
+*/
+func findSplit(node *treeNode, X [][]float64, y []float64, depth int, used []int, context *treeContext) *splitCandidate {
+	options := context.options
 
+	pure := true
+	for _, v := range y { pure = pure && v == y[0] }
 
-			// what index should we split the dataset by?
-			ix := splitDataset(X, y, node.splitColumn, node.splitVal)
+	// should we split this node further?
+	// 1) must not exceed maxDepth
+	// 2) must have enough samples to split, and to fill both leaves
+	// 3) must have samples of more than one class (or response)
+	should_split := depth < options.MaxDepth &&
+		node.size >= options.MinSamplesSplit &&
+		node.size >= 2 * options.MinSamplesLeaf &&
+		!pure
+	if !should_split {
+		return nil
+	}
 
-			// we can no longer use this column
-			used := context.used
-			used[node.splitColumn] = 1
+	// find best splitting column we haven't used
+	result := bestSplit(X, y, used, context)
 
-			// fit sub trees, fix context
-			context.curDepth = myDepth + 1
-			context.used = copySlice(used)
-			node.left = fitTree(X[:ix], y[:ix], context)
+	// did we successfully split?
+	if result.splitColumn == -1 {
+		return nil
+	}
 
-			context.curDepth = myDepth + 1
-			context.used = copySlice(used)
-			node.right = fitTree(X[ix:], y[ix:], context)
-		}
+	// impurity decrease weighted by the node's share of the samples
+	decrease := float64(node.size) / float64(context.nSamples) * (result.parent - result.impurity)
+	if options.MinImpurityDecrease > 0 && decrease < options.MinImpurityDecrease {
+		return nil
 	}
 
-	return node
+	return &splitCandidate{node, X, y, depth, used, result, decrease}
 }
 
 
@@ -220,18 +526,18 @@ func fitTree(X [][]float64, y []float64, context *treeContext) *treeNode {
  NOTE: uses CPU-bound go-routines, increase runtime.GOMAXPROCS for
  multicore processing and a generous speed-up
 */
-func bestSplit(X [][]float64, y []float64, context *treeContext) splitResult {
+func bestSplit(X [][]float64, y []float64, used []int, context *treeContext) splitResult {
 	nFeatures := len(X[0])
 	results := make(chan splitResult, nFeatures)
 	wg := new(sync.WaitGroup)
 
 	for i := 0; i < nFeatures; i++ {
-		if context.used[i] != 1 {
+		if used[i] != 1 {
 			wg.Add(1)
 			go func(i int) {
 				defer wg.Done()
-				impurity, val := context.splitter(X, y, i)
-				results <- splitResult{impurity, i, val}
+				impurity, val, parent := context.splitter(X, y, i, context.options.MinSamplesLeaf, context.nClasses)
+				results <- splitResult{impurity, i, val, parent}
 			}(i)
 		}
 	}
@@ -239,7 +545,7 @@ func bestSplit(X [][]float64, y []float64, context *treeContext) splitResult {
 	wg.Wait()
 	close(results)
 
-	bestResult := splitResult{1.1, -1, 0.0}
+	bestResult := splitResult{math.Inf(1), -1, 0.0, 0.0}
 	for result := range results {
 		if result.impurity < bestResult.impurity {
 			bestResult = result
@@ -260,26 +566,21 @@ func bestSplit(X [][]float64, y []float64, context *treeContext) splitResult {
                left sub tree.
 */
 func splitDataset(X [][]float64, y []float64, splitColumn int, splitVal float64) int {
-	rearIndex := len(X) - 1
 	splitPoint := 0
-	for i := 0; i < rearIndex; i++ {
-		if X[i][splitColumn] >= splitVal {
-			X[i], X[rearIndex] = X[rearIndex], X[i]
-			X[i], X[i+1] = X[i+1], X[i]
-			y[i], y[rearIndex] = y[rearIndex], y[i]
-			y[i], y[i+1] = y[i+1], y[i]
-			rearIndex--
-		} else {
-			splitPoint = i
+	for i := range X {
+		if X[i][splitColumn] < splitVal {
+			X[i], X[splitPoint] = X[splitPoint], X[i]
+			y[i], y[splitPoint] = y[splitPoint], y[i]
+			splitPoint++
 		}
 	}
 
-	return splitPoint + 1
+	return splitPoint
 }
 
 
 func copySlice(slice []int) []int {
 	newSlice := make([]int, len(slice))
 	copy(newSlice, slice)
-	return slice
+	return newSlice
 }
\ No newline at end of file
@@ -2,8 +2,11 @@ package decision_tree
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"runtime"
 	"testing"
+	"github.com/emef/go.ml/matrix"
 	"github.com/emef/go.ml/metrics"
 	"github.com/emef/go.ml/datasets"
 )
@@ -59,3 +62,477 @@ func testDataset(X [][]float64, y []float64) float64 {
 
 	return foldSum / float64(kFolds)
 }
+
+func TestMulticlass(t *testing.T) {
+	// three classes with arbitrary labels: 9 left of 0.5 on column 0,
+	// then 2 below and 5 above 0.5 on column 1
+	n := 150
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		switch i % 3 {
+		case 0:
+			X[i] = []float64{0.5 * rand.Float64(), rand.Float64()}
+			y[i] = 9
+		case 1:
+			X[i] = []float64{0.5 + 0.5*rand.Float64(), 0.5 * rand.Float64()}
+			y[i] = 2
+		case 2:
+			X[i] = []float64{0.5 + 0.5*rand.Float64(), 0.5 + 0.5*rand.Float64()}
+			y[i] = 5
+		}
+	}
+	X0, y0 := X[0], y[0]
+
+	tree, _ := DecisionTree(3, GINI)
+	tree.Fit(X, y)
+
+	if X[0][0] != X0[0] || y[0] != y0 {
+		t.Errorf("Fit should not reorder the training samples")
+	}
+	if acc := metrics.Accuracy(tree.Classify(X), y); acc != 1 {
+		t.Errorf("training accuracy %.3f, expected 1", acc)
+	}
+
+	root := tree.root
+	if len(root.counts) != 3 || root.counts[0] != 50 || root.counts[1] != 50 || root.counts[2] != 50 {
+		t.Errorf("root class counts %v, expected [50 50 50]", root.counts)
+	}
+	if root.splitColumn != 0 {
+		t.Errorf("root split on column %d, expected 0", root.splitColumn)
+	}
+}
+
+func TestMulticlassGini(t *testing.T) {
+	X := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}}
+	y := []float64{0, 0, 1, 1, 2, 2}
+
+	// best split isolates one pure pair: 4/6 * (1 - 2 * (1/2)^2) = 1/3
+	gini, split, _ := splitGINI(X, y, 0, 1, 3)
+	if math.Abs(gini-1.0/3) > 1e-12 || (split != 3 && split != 5) {
+		t.Errorf("gini %.4f at %.1f, expected 0.3333 at 3 or 5", gini, split)
+	}
+}
+
+func TestSplitDataset(t *testing.T) {
+	X := [][]float64{{4}, {1}, {2}, {0}, {3}, {2}, {1}}
+	y := []float64{4, 1, 2, 0, 3, 2, 1}
+
+	ix := splitDataset(X, y, 0, 2)
+	if ix != 3 {
+		t.Errorf("split point %d, expected 3", ix)
+	}
+	for i := range X {
+		if (i < ix) != (X[i][0] < 2) || y[i] != X[i][0] {
+			t.Errorf("bad split %v at %d", X, ix)
+			break
+		}
+	}
+}
+
+func TestRegressionTree(t *testing.T) {
+	// piecewise constant response: 3 left of 0.5 on column 0, otherwise
+	// 1 below and -2 above 0.5 on column 1
+	n := 300
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	clean := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rand.Float64(), rand.Float64()}
+		switch {
+		case X[i][0] < 0.5:
+			clean[i] = 3
+		case X[i][1] < 0.5:
+			clean[i] = 1
+		default:
+			clean[i] = -2
+		}
+		y[i] = clean[i] + 0.1 * rand.NormFloat64()
+	}
+	y0 := y[0]
+
+	for _, method := range []string{MSE, MAE, FRIEDMAN_MSE} {
+		tree, err := RegressionTree(2, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Fit(X, y)
+
+		if y[0] != y0 {
+			t.Errorf("%s: Fit should not reorder the responses", method)
+		}
+		if tree.root.splitColumn != 0 {
+			t.Errorf("%s: root split on column %d, expected 0", method, tree.root.splitColumn)
+		}
+		// samples near a boundary may land on either side, MAE in
+		// particular is often indifferent to where exactly it splits
+		close := 0
+		for i, v := range tree.Predict(X) {
+			if math.Abs(v - clean[i]) < 0.5 {
+				close++
+			}
+		}
+		if close < n * 95 / 100 {
+			t.Errorf("%s: %d of %d predictions near the noiseless response", method, close, n)
+		}
+	}
+
+	if _, err := RegressionTree(2, GINI); err == nil {
+		t.Errorf("expected an error for a classification criterion")
+	}
+}
+
+func TestRegressionLeaves(t *testing.T) {
+	X := [][]float64{{0}, {0}, {0}, {1}, {1}, {1}}
+	y := []float64{1, 2, 30, 5, 5, 5}
+
+	tree, _ := RegressionTree(1, MAE)
+	tree.Fit(X, y)
+	if yPred := tree.Predict([][]float64{{0}, {1}}); yPred[0] != 2 || yPred[1] != 5 {
+		t.Errorf("MAE leaves %v, expected medians [2 5]", yPred)
+	}
+
+	tree, _ = RegressionTree(1, MSE)
+	tree.Fit(X, y)
+	if yPred := tree.Predict([][]float64{{0}, {1}}); yPred[0] != 11 || yPred[1] != 5 {
+		t.Errorf("MSE leaves %v, expected means [11 5]", yPred)
+	}
+}
+
+func TestRegressionCriteria(t *testing.T) {
+	// compare the incremental scans to the impurity of every split
+	// point computed from scratch
+	n := 40
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{float64(rand.Intn(15))}
+		y[i] = rand.ExpFloat64()
+	}
+
+	bruteForce := func(loss func([]float64) float64) (float64, float64) {
+		best, split := math.Inf(1), 0.0
+		for _, row := range X {
+			s := row[0]
+			var left, right []float64
+			for i := range X {
+				if X[i][0] < s {
+					left = append(left, y[i])
+				} else {
+					right = append(right, y[i])
+				}
+			}
+			if len(left) == 0 || len(right) == 0 {
+				continue
+			}
+			if v := (loss(left) + loss(right)) / float64(n); v < best-1e-12 || (v < best+1e-12 && s < split) {
+				best, split = v, s
+			}
+		}
+		return best, split
+	}
+	sse := func(v []float64) float64 {
+		m, s := mean(v), 0.0
+		for _, x := range v { s += (x - m) * (x - m) }
+		return s
+	}
+	sad := func(v []float64) float64 {
+		m, s := median(v), 0.0
+		for _, x := range v { s += math.Abs(x - m) }
+		return s
+	}
+
+	wantMSE, wantSplit := bruteForce(sse)
+	gotMSE, gotSplit, _ := splitMSE(X, y, 0, 1, 0)
+	if math.Abs(gotMSE-wantMSE) > 1e-9 || gotSplit != wantSplit {
+		t.Errorf("mse %.6f at %.0f, expected %.6f at %.0f", gotMSE, gotSplit, wantMSE, wantSplit)
+	}
+	if _, split, _ := splitFriedmanMSE(X, y, 0, 1, 0); split != wantSplit {
+		t.Errorf("friedman split at %.0f, expected %.0f as for mse", split, wantSplit)
+	}
+
+	// the absolute error is piecewise linear, so ties between split
+	// points are common; compare the impurity alone
+	wantMAE, _ := bruteForce(sad)
+	if gotMAE, _, _ := splitMAE(X, y, 0, 1, 0); math.Abs(gotMAE-wantMAE) > 1e-9 {
+		t.Errorf("mae %.6f, expected %.6f", gotMAE, wantMAE)
+	}
+}
+
+func TestSplitCriteria(t *testing.T) {
+	X := [][]float64{{1}, {2}, {3}, {4}, {5}, {6}}
+	y := []float64{0, 0, 1, 1, 2, 2}
+
+	// isolating one pure pair leaves 4/6 * 1 bit of entropy
+	entropy, split, _ := impuritySplitter(Entropy)(X, y, 0, 1, 3)
+	if math.Abs(entropy-2.0/3) > 1e-12 || (split != 3 && split != 5) {
+		t.Errorf("entropy %.4f at %.1f, expected 0.6667 at 3 or 5", entropy, split)
+	}
+
+	logLoss, _, _ := impuritySplitter(LogLoss)(X, y, 0, 1, 3)
+	if math.Abs(logLoss-2.0/3*math.Ln2) > 1e-12 {
+		t.Errorf("log loss %.4f, expected %.4f", logLoss, 2.0/3*math.Ln2)
+	}
+
+	// gain log2(3) - 2/3 over split info H(1/3, 2/3)
+	ratio, _, _ := splitGainRatio(X, y, 0, 1, 3)
+	want := (math.Log2(3) - 2.0/3) / Entropy([]float64{1.0 / 3, 2.0 / 3})
+	if math.Abs(ratio+want) > 1e-12 {
+		t.Errorf("gain ratio %.4f, expected %.4f", -ratio, want)
+	}
+}
+
+func TestImpurityClassCount(t *testing.T) {
+	// the node holds no samples of class 2, but the impurity still sees
+	// the proportions of all three classes
+	X := [][]float64{{1}, {2}, {3}, {4}}
+	y := []float64{0, 0, 1, 1}
+
+	sizes := make(map[int]bool)
+	record := func(p []float64) float64 {
+		sizes[len(p)] = true
+		return Gini(p)
+	}
+	impuritySplitter(record)(X, y, 0, 1, 3)
+	if len(sizes) != 1 || !sizes[3] {
+		t.Errorf("impurity called with %v proportions, expected 3", sizes)
+	}
+}
+
+func TestGenericGini(t *testing.T) {
+	// the incremental Gini scan should agree with the generic one
+	X := make([][]float64, 100)
+	y := make([]float64, 100)
+	for i := range X {
+		X[i] = []float64{float64(rand.Intn(20))}
+		y[i] = float64(rand.Intn(4))
+	}
+
+	gini, split, _ := splitGINI(X, y, 0, 1, 4)
+	generic, genericSplit, _ := impuritySplitter(Gini)(X, y, 0, 1, 4)
+	if math.Abs(gini-generic) > 1e-12 || split != genericSplit {
+		t.Errorf("gini %.6f at %.0f, generic %.6f at %.0f", gini, split, generic, genericSplit)
+	}
+}
+
+func TestRegisterImpurity(t *testing.T) {
+	misclassification := func(p []float64) float64 {
+		return 1 - p[matrix.VecArgMax(p)]
+	}
+	if err := RegisterImpurity("misclassification", misclassification); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(splitters, "misclassification")
+	if err := RegisterImpurity("misclassification", misclassification); err == nil {
+		t.Errorf("expected an error registering a name twice")
+	}
+	if err := RegisterImpurity(GINI, misclassification); err == nil {
+		t.Errorf("expected an error replacing a built in method")
+	}
+
+	X, y := datasets.Load("iris")
+	for _, method := range []string{ENTROPY, GAIN_RATIO, LOG_LOSS, "misclassification"} {
+		tree, err := DecisionTree(3, method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Fit(X, y)
+		if acc := metrics.Accuracy(tree.Classify(X), y); acc < 0.9 {
+			t.Errorf("%s: iris training accuracy %.3f", method, acc)
+		}
+	}
+}
+
+// leaves of the tree rooted at node, depth first
+func leaves(node *treeNode) []*treeNode {
+	if node.isLeaf() {
+		return []*treeNode{node}
+	}
+	return append(leaves(node.left), leaves(node.right)...)
+}
+
+func TestMinSamples(t *testing.T) {
+	X, y := datasets.Load("cancer")
+
+	options := NewTreeOptions(20)
+	options.MinSamplesSplit = 30
+	options.MinSamplesLeaf = 10
+	tree, err := DecisionTreeWithOptions(GINI, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Fit(X, y)
+
+	var check func(*treeNode)
+	check = func(node *treeNode) {
+		if node.isLeaf() {
+			if node.size < options.MinSamplesLeaf {
+				t.Errorf("leaf with %d samples", node.size)
+			}
+			return
+		}
+		if node.size < options.MinSamplesSplit {
+			t.Errorf("split a node with %d samples", node.size)
+		}
+		check(node.left)
+		check(node.right)
+	}
+	check(tree.root)
+}
+
+func TestMaxLeafNodes(t *testing.T) {
+	// splitting on column 0 matters most, then column 1 matters much
+	// more on the right than on the left
+	n := 400
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rand.Float64(), rand.Float64()}
+		if X[i][0] >= 0.5 {
+			y[i] = 20
+			if X[i][1] < 0.5 { y[i] += 5 }
+		} else if X[i][1] < 0.5 {
+			y[i] += 1
+		}
+	}
+
+	options := NewTreeOptions(10)
+	options.MaxLeafNodes = 3
+	tree, _ := RegressionTreeWithOptions(MSE, options)
+	tree.Fit(X, y)
+
+	if nLeaves := len(leaves(tree.root)); nLeaves != 3 {
+		t.Errorf("%d leaves, expected 3", nLeaves)
+	}
+	if tree.root.splitColumn != 0 || !tree.root.left.isLeaf() || tree.root.right.isLeaf() {
+		t.Errorf("expected the right subtree to be split first:\n%v", tree)
+	}
+
+	// without the limit every leaf is pure
+	tree, _ = RegressionTree(10, MSE)
+	tree.Fit(X, y)
+	if nLeaves := len(leaves(tree.root)); nLeaves != 4 {
+		t.Errorf("%d leaves without a limit, expected 4", nLeaves)
+	}
+}
+
+func TestReuseFeatures(t *testing.T) {
+	// three segments along a single column need two splits on it
+	X := make([][]float64, 90)
+	y := make([]float64, 90)
+	for i := range X {
+		X[i] = []float64{float64(i)}
+		y[i] = float64(i / 30)
+	}
+
+	tree, _ := DecisionTree(5, GINI)
+	tree.Fit(X, y)
+	if acc := metrics.Accuracy(tree.Classify(X), y); acc == 1 {
+		t.Errorf("column 0 should only be split once")
+	}
+
+	options := NewTreeOptions(5)
+	options.ReuseFeatures = true
+	tree, _ = DecisionTreeWithOptions(GINI, options)
+	tree.Fit(X, y)
+	if acc := metrics.Accuracy(tree.Classify(X), y); acc != 1 {
+		t.Errorf("training accuracy %.3f reusing features, expected 1", acc)
+	}
+}
+
+func TestMinImpurityDecrease(t *testing.T) {
+	// a small step in the response, and a large one
+	n := 200
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rand.Float64(), rand.Float64()}
+		if X[i][0] < 0.5 { y[i] += 0.2 }
+		if X[i][1] < 0.5 { y[i] += 4 }
+	}
+
+	// the large step decreases the mse by about 4, the small one by 0.01
+	options := NewTreeOptions(5)
+	options.MinImpurityDecrease = 0.1
+	tree, _ := RegressionTreeWithOptions(MSE, options)
+	tree.Fit(X, y)
+
+	if tree.root.splitColumn != 1 || !tree.root.left.isLeaf() || !tree.root.right.isLeaf() {
+		t.Errorf("expected a single split on column 1:\n%v", tree)
+	}
+}
+
+func TestTreeOptions(t *testing.T) {
+	bad := []TreeOptions{
+		{MaxDepth: -1, MinSamplesSplit: 2, MinSamplesLeaf: 1},
+		{MaxDepth: 3, MinSamplesSplit: 1, MinSamplesLeaf: 1},
+		{MaxDepth: 3, MinSamplesSplit: 2, MinSamplesLeaf: 0},
+		{MaxDepth: 3, MinSamplesSplit: 2, MinSamplesLeaf: 1, MinImpurityDecrease: -1},
+		{MaxDepth: 3, MinSamplesSplit: 2, MinSamplesLeaf: 1, MaxLeafNodes: 1},
+	}
+	for _, options := range bad {
+		if _, err := DecisionTreeWithOptions(GINI, options); err == nil {
+			t.Errorf("expected an error for %+v", options)
+		}
+		if _, err := RegressionTreeWithOptions(MSE, options); err == nil {
+			t.Errorf("expected an error for %+v", options)
+		}
+	}
+}
+
+func TestPredictProba(t *testing.T) {
+	X := [][]float64{{0}, {0}, {0}, {0}, {1}, {1}, {1}, {1}}
+	y := []float64{7, 7, 7, 3, 3, 3, 3, 3}
+
+	tree, _ := DecisionTree(1, GINI)
+	tree.Fit(X, y)
+	if classes := tree.Classes(); len(classes) != 2 || classes[0] != 3 || classes[1] != 7 {
+		t.Fatalf("classes %v, expected [3 7]", classes)
+	}
+
+	proba := tree.PredictProba([][]float64{{0}, {1}})
+	if proba[0][0] != 0.25 || proba[0][1] != 0.75 || proba[1][0] != 1 || proba[1][1] != 0 {
+		t.Errorf("probabilities %v, expected [[0.25 0.75] [1 0]]", proba)
+	}
+
+	if err := tree.SetSmoothing(-1); err == nil {
+		t.Error("expected error for negative smoothing")
+	}
+
+	// Laplace smoothing: (n_k + 1) / (n + 2)
+	if err := tree.SetSmoothing(1); err != nil {
+		t.Fatal(err)
+	}
+	proba = tree.PredictProba([][]float64{{0}, {1}})
+	want := [][]float64{{2.0 / 6, 4.0 / 6}, {5.0 / 6, 1.0 / 6}}
+	for i := range want {
+		for k := range want[i] {
+			if math.Abs(proba[i][k]-want[i][k]) > 1e-12 {
+				t.Errorf("smoothed probabilities %v, expected %v", proba, want)
+			}
+		}
+	}
+
+	regression, _ := RegressionTree(1, MSE)
+	regression.Fit(X, y)
+	if regression.PredictProba(X) != nil {
+		t.Errorf("expected no probabilities from a regression tree")
+	}
+}
+
+func TestPredictProbaIris(t *testing.T) {
+	X, y := datasets.Load("iris")
+	tree, _ := DecisionTree(3, ENTROPY)
+	tree.Fit(X, y)
+
+	// rows sum to one and their most likely class is the prediction
+	yPred := tree.Classify(X)
+	for i, p := range tree.PredictProba(X) {
+		sum := 0.0
+		for _, v := range p { sum += v }
+		if math.Abs(sum-1) > 1e-12 || tree.Classes()[matrix.VecArgMax(p)] != yPred[i] {
+			t.Errorf("sample %d: probabilities %v, predicted %v", i, p, yPred[i])
+			break
+		}
+	}
+}
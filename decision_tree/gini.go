@@ -1,8 +1,8 @@
 package decision_tree
 
 import (
-	"sort"
 	"math"
+	"sort"
 )
 
 // zips two values and allows sorting based on value
@@ -19,82 +19,153 @@ func (a zipColumnSortable) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a zipColumnSortable) Less(i, j int) bool { return a[i].Value < a[j].Value }
 
 
-/*
- Split dataset on column according to GINI criteria.
+// impurity criterion evaluated incrementally while scanning a sorted column
+type scanCriterion interface {
+	init(y []float64)    // responses in column order, all in the right subtree
+	moveLeft(y float64)  // move the next response into the left subtree
+	impurity() float64   // impurity of the current split, lower is better
+	parent() float64     // impurity of the unsplit node
+}
 
- GINI calculated as:
-   P(t) = probability of a sample belonging to subtree
-   P(1|t) = probability of pos sample in subtree
-   G(t) = 1 - P(1|t)^2 - P(0|t)^2
-   GINI = P(t_l)*G(t_l) + P(t_r)*G(t_r)
-     where t_l and t_r are left and right subtrees after some split
+
+/*
+ Scans every split point of a column in sorted order, letting the
+ criterion update its statistics one sample at a time.
 
  Arguments
  --------
-   X:     training dataset
-   y:     training responses
-   index: column index to split by
+   X:         training dataset
+   y:         training responses
+   index:     column index to split by
+   minLeaf:   fewest samples each side of the split must keep
+   criterion: impurity to minimize
 
  Returns
  -------
-   (minGini, split)
+   (minImpurity, split, parent)
    where
-     minGini: minimum Gini impurity value
-     split:   value at which we split this column
+     minImpurity: minimum impurity value, +Inf if there is no valid split
+     split:       value at which we split this column
+     parent:      impurity of the node before splitting
 */
-func splitGINI(X [][]float64, y []float64, index int) (float64, float64) {
+func scanColumn(X [][]float64, y []float64, index int, minLeaf int, criterion scanCriterion) (float64, float64, float64) {
 	N := len(X)
-	totalPositive := 0.0
 
 	// zip the column values and corresponding responses
 	col := make([]zipColumn, N)
 	for i := range X {
 		col[i] = zipColumn{X[i][index], y[i]}
-		totalPositive += y[i]
 	}
 
 	// sort the column by value
 	sort.Sort(zipColumnSortable(col))
 
-	nPositiveL := 0.0
-	minGini := 1.1
+	ys := make([]float64, N)
+	for i := range col { ys[i] = col[i].Response }
+	criterion.init(ys)
+
+	minImpurity := math.Inf(1)
 	split := col[0].Value
 
-	// investigate every possible split point, track minimum GINI
+	// investigate every possible split point, track minimum impurity
 	for i := 1; i < N; i++ {
-		// we already split on this value! continue
-		if col[i].Value == col[i-1].Value {
-			nPositiveL += col[i-1].Response
+		criterion.moveLeft(ys[i-1])
+
+		// we already split on this value, or a side would be too small! continue
+		if col[i].Value == col[i-1].Value || i < minLeaf || N - i < minLeaf {
 			continue
 		}
 
-		// calculate P(1|t_l) and P(0|t_l)
-		nPositiveL += col[i-1].Response
-		pPositiveL := nPositiveL / float64(i)
-		pNegativeL := 1 - pPositiveL
+		// record the best split so far
+		if impurity := criterion.impurity(); impurity < minImpurity {
+			minImpurity = impurity
+			split = col[i].Value
+		}
+	}
 
-		// infer P(1|t_r) and P(0|t_r) from left tree
-		nPositiveR := totalPositive - nPositiveL
-		pPositiveR := nPositiveR / float64(N - i)
-		pNegativeR := 1 - pPositiveR
+	return minImpurity, split, criterion.parent()
+}
 
-		// calculate G(t_l) and G(t_r)
-		giniL := 1 - math.Pow(pPositiveL, 2) - math.Pow(pNegativeL, 2)
-		giniR := 1 - math.Pow(pPositiveR, 2) - math.Pow(pNegativeR, 2)
 
-		// calculate P(t_l) and P(t_r)
-		pL := float64(i) / float64(N)
-		pR := 1 - pL
+/*
+ Split dataset on column according to GINI criteria.
 
-		// put everything together to calculate GINI for this split point
-		gini := pL * giniL + pR * giniR
+ GINI calculated as:
+   P(t) = probability of a sample belonging to subtree
+   P(k|t) = probability of class k sample in subtree
+   G(t) = 1 - sum_k P(k|t)^2
+   GINI = P(t_l)*G(t_l) + P(t_r)*G(t_r)
+     where t_l and t_r are left and right subtrees after some split
+
+ Arguments
+ --------
+   X:     training dataset
+   y:     training class indices in {0, ..., K-1}
+   index:   column index to split by
+   minLeaf: fewest samples each side of the split must keep
+   nClasses: number of classes K in the whole training set
+
+ Returns
+ -------
+   (minGini, split, parentGini)
+   where
+     minGini:    minimum Gini impurity value
+     split:      value at which we split this column
+     parentGini: Gini impurity of the unsplit node
+*/
+func splitGINI(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64) {
+	return scanColumn(X, y, index, minLeaf, &giniCriterion{nClasses: nClasses})
+}
+
+
+// class counts on each side of the split, and their sums of squares
+type giniCriterion struct {
+	nClasses         int
+	countsL, countsR []float64
+	sumSqL, sumSqR   float64
+	nL, nR           float64
+	parentGini       float64
+}
+
+
+func (c *giniCriterion) init(y []float64) {
+	// start with every sample on the right
+	c.countsL = make([]float64, c.nClasses)
+	c.countsR = make([]float64, c.nClasses)
+	for _, v := range y { c.countsR[int(v)]++ }
+	c.sumSqL, c.sumSqR = 0, 0
+	for _, n := range c.countsR { c.sumSqR += n * n }
+	c.nL, c.nR = 0, float64(len(y))
+	c.parentGini = 1 - c.sumSqR / (c.nR * c.nR)
+}
+
+
+// updates the sums of squared counts incrementally
+func (c *giniCriterion) moveLeft(y float64) {
+	k := int(y)
+	c.sumSqL += 2 * c.countsL[k] + 1
+	c.sumSqR -= 2 * c.countsR[k] - 1
+	c.countsL[k]++
+	c.countsR[k]--
+	c.nL++
+	c.nR--
+}
+
+
+func (c *giniCriterion) impurity() float64 {
+	// calculate G(t_l) and G(t_r), sum_k P(k|t)^2 = sum_k n_k^2 / n^2
+	giniL := 1 - c.sumSqL / (c.nL * c.nL)
+	giniR := 1 - c.sumSqR / (c.nR * c.nR)
+
+	// calculate P(t_l) and P(t_r)
+	pL := c.nL / (c.nL + c.nR)
+	pR := 1 - pL
+
+	// put everything together to calculate GINI for this split point
+	return pL * giniL + pR * giniR
+}
 
-		// record the best split so far
-		if gini < minGini {
-			minGini = gini
-			split = col[i].Value
-		}
-	}
 
-	return minGini, split
+func (c *giniCriterion) parent() float64 {
+	return c.parentGini
 }
\ No newline at end of file
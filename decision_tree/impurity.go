@@ -0,0 +1,182 @@
+package decision_tree
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+const ENTROPY = "entropy"
+const GAIN_RATIO = "gain_ratio"
+const LOG_LOSS = "log_loss"
+
+/*
+ impurity of a node given the proportion p[k] of its samples in each
+ class k of the training set, including classes absent from the node;
+ should be 0 for a pure node and larger the more mixed the
+ classes are. Splits minimize the impurity of the two subtrees weighted
+ by their sizes.
+*/
+type Impurity func(p []float64) float64
+
+// splitting methods known to DecisionTree, by name
+var splitters = map[string]splitFunction{
+	GINI:       splitGINI,
+	ENTROPY:    impuritySplitter(Entropy),
+	LOG_LOSS:   impuritySplitter(LogLoss),
+	GAIN_RATIO: splitGainRatio,
+}
+var splittersLock sync.RWMutex
+
+
+/*
+ registers a custom impurity so that DecisionTree accepts it as a
+ splitting method
+
+ arguments
+ ---------
+   name:     splitting method name to pass to DecisionTree
+   impurity: impurity function of the class proportions
+
+ returns
+ -------
+   error if the name is already taken
+*/
+func RegisterImpurity(name string, impurity Impurity) error {
+	if impurity == nil {
+		return errors.New("impurity function is nil")
+	}
+
+	splittersLock.Lock()
+	defer splittersLock.Unlock()
+
+	if _, ok := splitters[name]; ok {
+		return errors.New("splitting method already registered")
+	}
+	splitters[name] = impuritySplitter(impurity)
+	return nil
+}
+
+
+func lookupSplitter(name string) (splitFunction, bool) {
+	splittersLock.RLock()
+	defer splittersLock.RUnlock()
+	splitter, ok := splitters[name]
+	return splitter, ok
+}
+
+
+// G = 1 - sum_k p_k^2
+func Gini(p []float64) float64 {
+	g := 1.0
+	for _, v := range p { g -= v * v }
+	return g
+}
+
+
+// H = -sum_k p_k log2(p_k), in bits; splitting on it maximizes information gain
+func Entropy(p []float64) float64 {
+	return LogLoss(p) / math.Ln2
+}
+
+
+// -sum_k p_k ln(p_k): the mean log loss on the training samples when a
+// leaf predicts its class proportions; entropy in nats
+func LogLoss(p []float64) float64 {
+	h := 0.0
+	for _, v := range p {
+		if v > 0 {
+			h -= v * math.Log(v)
+		}
+	}
+	return h
+}
+
+
+// splitFunction minimizing the weighted impurity of the subtrees
+func impuritySplitter(impurity Impurity) splitFunction {
+	return func(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64) {
+		return scanColumn(X, y, index, minLeaf, &impurityCriterion{measure: impurity, nClasses: nClasses})
+	}
+}
+
+
+/*
+ Split dataset on column maximizing C4.5's gain ratio, the information
+ gain normalized by the entropy of the split itself:
+
+   gain = H(t) - P(t_l)*H(t_l) - P(t_r)*H(t_r)
+   splitInfo = -P(t_l) log2 P(t_l) - P(t_r) log2 P(t_r)
+   ratio = gain / splitInfo
+
+ Information gain favors splits that peel off many small pure groups;
+ the ratio penalizes them.
+
+ Returns
+ -------
+   (-maxRatio, split, 0), negated so that lower is better, and with 0 for
+   the unsplit node so that the impurity decrease is the ratio itself
+*/
+func splitGainRatio(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64) {
+	return scanColumn(X, y, index, minLeaf, &impurityCriterion{measure: Entropy, gainRatio: true, nClasses: nClasses})
+}
+
+
+// class counts on each side of the split
+type impurityCriterion struct {
+	measure          Impurity
+	gainRatio        bool
+	nClasses         int        // classes in the training set, not just this node
+	countsL, countsR []float64
+	nL, nR           float64
+	parentImpurity   float64    // impurity before splitting
+	p                []float64  // buffer for class proportions
+}
+
+
+func (c *impurityCriterion) init(y []float64) {
+	// start with every sample on the right
+	c.countsL = make([]float64, c.nClasses)
+	c.countsR = make([]float64, c.nClasses)
+	for _, v := range y { c.countsR[int(v)]++ }
+	c.nL, c.nR = 0, float64(len(y))
+	c.p = make([]float64, c.nClasses)
+	c.parentImpurity = c.nodeImpurity(c.countsR, c.nR)
+}
+
+
+func (c *impurityCriterion) moveLeft(y float64) {
+	c.countsL[int(y)]++
+	c.countsR[int(y)]--
+	c.nL++
+	c.nR--
+}
+
+
+func (c *impurityCriterion) impurity() float64 {
+	pL := c.nL / (c.nL + c.nR)
+	pR := 1 - pL
+	weighted := pL * c.nodeImpurity(c.countsL, c.nL) + pR * c.nodeImpurity(c.countsR, c.nR)
+
+	if c.gainRatio {
+		splitInfo := Entropy([]float64{pL, pR})
+		return -(c.parentImpurity - weighted) / splitInfo
+	}
+	return weighted
+}
+
+
+func (c *impurityCriterion) parent() float64 {
+	if c.gainRatio {
+		return 0
+	}
+	return c.parentImpurity
+}
+
+
+func (c *impurityCriterion) nodeImpurity(counts []float64, n float64) float64 {
+	for k := range counts {
+		c.p[k] = counts[k] / n
+	}
+	return c.measure(c.p)
+}
\ No newline at end of file
@@ -0,0 +1,233 @@
+package decision_tree
+
+import (
+	"container/heap"
+	"sort"
+)
+
+const MSE = "mse"
+const MAE = "mae"
+const FRIEDMAN_MSE = "friedman_mse"
+
+
+/*
+ Split dataset on column minimizing the mean squared error of predicting
+ each subtree by its mean, i.e. maximizing the variance reduction.
+
+ MSE calculated as:
+   SSE(t) = sum_{i in t} (y_i - mean(t))^2
+          = sum_{i in t} y_i^2 - (sum_{i in t} y_i)^2 / n(t)
+   MSE = (SSE(t_l) + SSE(t_r)) / n
+
+ Returns
+ -------
+   (minMSE, split, SSE(t) / n)
+*/
+func splitMSE(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64) {
+	return scanColumn(X, y, index, minLeaf, new(mseCriterion))
+}
+
+
+/*
+ Split dataset on column minimizing the mean absolute error of predicting
+ each subtree by its median; more robust to outlying responses than MSE.
+
+ MAE calculated as:
+   SAD(t) = sum_{i in t} |y_i - median(t)|
+   MAE = (SAD(t_l) + SAD(t_r)) / n
+
+ Returns
+ -------
+   (minMAE, split, SAD(t) / n)
+*/
+func splitMAE(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64) {
+	return scanColumn(X, y, index, minLeaf, new(maeCriterion))
+}
+
+
+/*
+ Split dataset on column by Friedman's improvement criterion (from
+ gradient boosting), which looks only at the difference of the subtree
+ means:
+
+   I = n(t_l) n(t_r) / n * (mean(t_l) - mean(t_r))^2
+
+ -I / n is returned so that lower is better, as for the other criteria,
+ with 0 for the unsplit node so that the impurity decrease is I / n.
+ With unweighted samples it ranks splits like MSE.
+
+ Returns
+ -------
+   (-maxImprovement / n, split, 0)
+*/
+func splitFriedmanMSE(X [][]float64, y []float64, index int, minLeaf int, nClasses int) (float64, float64, float64) {
+	return scanColumn(X, y, index, minLeaf, &mseCriterion{friedman: true})
+}
+
+
+// response sums and sums of squares on each side of the split
+type mseCriterion struct {
+	friedman       bool
+	sumL, sumR     float64
+	sumSqL, sumSqR float64
+	nL, nR         float64
+	parentMSE      float64
+}
+
+
+func (c *mseCriterion) init(y []float64) {
+	c.sumL, c.sumSqL, c.nL = 0, 0, 0
+	c.sumR, c.sumSqR, c.nR = 0, 0, float64(len(y))
+	for _, v := range y {
+		c.sumR += v
+		c.sumSqR += v * v
+	}
+	c.parentMSE = (c.sumSqR - c.sumR * c.sumR / c.nR) / c.nR
+}
+
+
+func (c *mseCriterion) moveLeft(y float64) {
+	c.sumL += y
+	c.sumSqL += y * y
+	c.nL++
+	c.sumR -= y
+	c.sumSqR -= y * y
+	c.nR--
+}
+
+
+func (c *mseCriterion) impurity() float64 {
+	n := c.nL + c.nR
+	if c.friedman {
+		diff := c.sumL / c.nL - c.sumR / c.nR
+		return -c.nL * c.nR / n * diff * diff / n
+	}
+
+	sseL := c.sumSqL - c.sumL * c.sumL / c.nL
+	sseR := c.sumSqR - c.sumR * c.sumR / c.nR
+	return (sseL + sseR) / n
+}
+
+
+func (c *mseCriterion) parent() float64 {
+	if c.friedman {
+		return 0
+	}
+	return c.parentMSE
+}
+
+
+// running median of the left subtree, and precomputed absolute
+// deviations of every suffix for the right subtree
+type maeCriterion struct {
+	left   *runningMedian
+	sadR   []float64  // sadR[i] = SAD of the responses from i on
+	nL     int
+}
+
+
+func (c *maeCriterion) init(y []float64) {
+	// the right subtree only loses samples, so build its deviations
+	// back to front by adding samples instead
+	c.sadR = make([]float64, len(y) + 1)
+	right := new(runningMedian)
+	for i := len(y) - 1; i >= 0; i-- {
+		right.add(y[i])
+		c.sadR[i] = right.absDev()
+	}
+	c.left = new(runningMedian)
+	c.nL = 0
+}
+
+
+func (c *maeCriterion) moveLeft(y float64) {
+	c.left.add(y)
+	c.nL++
+}
+
+
+func (c *maeCriterion) impurity() float64 {
+	return (c.left.absDev() + c.sadR[c.nL]) / float64(len(c.sadR) - 1)
+}
+
+
+func (c *maeCriterion) parent() float64 {
+	return c.sadR[0] / float64(len(c.sadR) - 1)
+}
+
+
+// min-heap of floats
+type floatHeap []float64
+
+func (h floatHeap) Len() int { return len(h) }
+func (h floatHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h floatHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *floatHeap) Push(x interface{}) { *h = append(*h, x.(float64)) }
+func (h *floatHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old) - 1]
+	*h = old[:len(old) - 1]
+	return x
+}
+
+
+/*
+ Median of a growing set of values kept as two heaps: the lower half
+ (negated, so the top is its maximum) and the upper half, along with
+ the sum of each half so that the sum of absolute deviations from the
+ median is available in constant time.
+*/
+type runningMedian struct {
+	lo, hi       floatHeap
+	sumLo, sumHi float64
+}
+
+
+func (m *runningMedian) add(v float64) {
+	if len(m.lo) == 0 || v <= -m.lo[0] {
+		heap.Push(&m.lo, -v)
+		m.sumLo += v
+	} else {
+		heap.Push(&m.hi, v)
+		m.sumHi += v
+	}
+
+	// keep the lower half the same size as the upper half, or one larger
+	if len(m.lo) > len(m.hi) + 1 {
+		x := -heap.Pop(&m.lo).(float64)
+		m.sumLo -= x
+		heap.Push(&m.hi, x)
+		m.sumHi += x
+	} else if len(m.hi) > len(m.lo) {
+		x := heap.Pop(&m.hi).(float64)
+		m.sumHi -= x
+		heap.Push(&m.lo, -x)
+		m.sumLo += x
+	}
+}
+
+
+// sum_i |v_i - median|, taking the lower median
+func (m *runningMedian) absDev() float64 {
+	med := -m.lo[0]
+	return med * float64(len(m.lo)) - m.sumLo + m.sumHi - med * float64(len(m.hi))
+}
+
+
+func mean(y []float64) float64 {
+	sum := 0.0
+	for _, v := range y { sum += v }
+	return sum / float64(len(y))
+}
+
+
+func median(y []float64) float64 {
+	sorted := make([]float64, len(y))
+	copy(sorted, y)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted) % 2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
\ No newline at end of file
@@ -0,0 +1,62 @@
+package kernel
+
+import (
+	"math"
+
+	"github.com/emef/go.ml/matrix"
+)
+
+// positive semi-definite similarity k(x, y) between two samples; any
+// function with this signature can be used as a user-defined kernel
+type Kernel func(x, y []float64) float64
+
+// k(x, y) = x'y
+func Linear() Kernel {
+	return func(x, y []float64) float64 {
+		return matrix.VecDot(x, y)
+	}
+}
+
+// k(x, y) = (gamma x'y + coef0)^degree
+func Polynomial(degree int, gamma, coef0 float64) Kernel {
+	return func(x, y []float64) float64 {
+		return math.Pow(gamma*matrix.VecDot(x, y)+coef0, float64(degree))
+	}
+}
+
+// k(x, y) = exp(-gamma ||x - y||^2)
+func RBF(gamma float64) Kernel {
+	return func(x, y []float64) float64 {
+		d := 0.0
+		for i := range x {
+			d += (x[i] - y[i]) * (x[i] - y[i])
+		}
+		return math.Exp(-gamma * d)
+	}
+}
+
+// k(x, y) = tanh(gamma x'y + coef0), which is not positive semi-definite
+// for every choice of parameters
+func Sigmoid(gamma, coef0 float64) Kernel {
+	return func(x, y []float64) float64 {
+		return math.Tanh(gamma*matrix.VecDot(x, y) + coef0)
+	}
+}
+
+/*
+ kernel (Gram) matrix between two sets of samples
+
+ returns
+ -------
+   K where K[i][j] = k(X[i], Y[j])
+*/
+func Matrix(k Kernel, X, Y [][]float64) [][]float64 {
+	K := make([][]float64, len(X))
+	for i := range X {
+		K[i] = make([]float64, len(Y))
+		for j := range Y {
+			K[i][j] = k(X[i], Y[j])
+		}
+	}
+	return K
+}
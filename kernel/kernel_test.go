@@ -0,0 +1,55 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+	"github.com/emef/go.ml/matrix"
+)
+
+func TestKernels(t *testing.T) {
+	x := []float64{1, 2}
+	y := []float64{3, -1}
+
+	cases := []struct {
+		name     string
+		k        Kernel
+		expected float64
+	}{
+		{"linear", Linear(), 1},
+		{"polynomial", Polynomial(2, 0.5, 1), 2.25},
+		{"rbf", RBF(0.1), math.Exp(-1.3)},
+		{"sigmoid", Sigmoid(0.5, 0), math.Tanh(0.5)},
+		{"user-defined", func(x, y []float64) float64 { return x[0] * y[0] }, 3},
+	}
+
+	for _, c := range cases {
+		if got := c.k(x, y); math.Abs(got-c.expected) > 1e-12 {
+			t.Errorf("%s kernel: got %.6f, expected %.6f", c.name, got, c.expected)
+		}
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	X := [][]float64{{0, 0}, {1, 0}, {0, 2}, {1, 1}}
+	K := Matrix(RBF(0.5), X, X)
+
+	for i := range K {
+		if K[i][i] != 1 {
+			t.Errorf("rbf diagonal should be 1, got %.6f", K[i][i])
+		}
+		for j := range K {
+			if K[i][j] != K[j][i] {
+				t.Errorf("kernel matrix not symmetric at (%d, %d)", i, j)
+			}
+		}
+	}
+
+	// positive definite for distinct samples
+	if _, err := matrix.Cholesky(K); err != nil {
+		t.Errorf("rbf kernel matrix should be positive definite: %v", err)
+	}
+
+	if K := Matrix(Linear(), X, X[:2]); len(K) != 4 || len(K[0]) != 2 {
+		t.Errorf("expected a 4 x 2 kernel matrix")
+	}
+}
@@ -0,0 +1,346 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+/*
+ Bayesian ridge regression: y ~ N(Xw + b, 1/alpha) with the prior
+ w ~ N(0, I/lambda). The precisions alpha and lambda are learned by
+ maximizing the evidence (marginal likelihood) with MacKay's fixed point
+ updates, under weak Gamma hyperpriors, so the amount of regularization
+ comes from the data. The posterior of w gives a predictive variance for
+ every sample.
+*/
+type BayesianRidge struct {
+	FitIntercept bool        // whether to fit an intercept term
+	Tol          float64     // total coefficient change at convergence
+	MaxIter      int         // maximum number of evidence updates
+	Alpha1       float64     // shape of the Gamma hyperprior on alpha
+	Alpha2       float64     // rate of the Gamma hyperprior on alpha
+	Lambda1      float64     // shape of the Gamma hyperprior on lambda
+	Lambda2      float64     // rate of the Gamma hyperprior on lambda
+	Coef         []float64   // posterior mean of the coefficients
+	Intercept    float64     // fitted intercept, 0 unless FitIntercept
+	Alpha        float64     // estimated noise precision
+	Lambda       float64     // estimated coefficient precision
+	Sigma        [][]float64 // posterior covariance of the coefficients
+	LogEvidence  float64     // log marginal likelihood at the estimates
+	NIter        int         // evidence updates used by the last fit
+	xMean        []float64   // feature means the model was centered on
+}
+
+/*
+ Bayesian ridge regression constructor
+
+ arguments
+ ---------
+   fitIntercept: whether to fit an intercept
+*/
+func NewBayesianRidge(fitIntercept bool) *BayesianRidge {
+	model := new(BayesianRidge)
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-3
+	model.MaxIter = 300
+	model.Alpha1, model.Alpha2 = 1e-6, 1e-6
+	model.Lambda1, model.Lambda2 = 1e-6, 1e-6
+	return model
+}
+
+/*
+ fit the model with samples (X) and responses (y). With X'X = V S V' the
+ posterior mean is V (S + lambda/alpha)^-1 V'X'y, so one
+ eigendecomposition serves every update of the precisions.
+*/
+func (model *BayesianRidge) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	Xc, yc, xMean, yMean := center(X, y, model.FitIntercept)
+	n, p := float64(len(Xc)), len(Xc[0])
+
+	s, V := matrix.SymmetricEigen(matrix.MatMultTrans(Xc, Xc))
+	Vty := matrix.VecMultTrans(V, matrix.VecMultTrans(Xc, yc))
+
+	alpha, lambda := 1/math.Max(variance(yc), 1e-12), 1.0
+	coef := make([]float64, p)
+	var rss float64
+
+	converged := false
+	for model.NIter = 1; model.NIter <= model.MaxIter; model.NIter++ {
+		// posterior mean for the current precisions
+		z := make([]float64, p)
+		for k := range z {
+			z[k] = Vty[k] / (s[k] + lambda/alpha)
+		}
+		next := matrix.VecMult(V, z)
+		rss = sumSquares(matrix.VecSub(yc, matrix.VecMult(Xc, next)))
+
+		// effective number of well-determined parameters
+		gamma := 0.0
+		for _, v := range s {
+			gamma += alpha * v / (lambda + alpha*v)
+		}
+
+		lambda = (gamma + 2*model.Lambda1) / (sumSquares(next) + 2*model.Lambda2)
+		alpha = (n - gamma + 2*model.Alpha1) / (rss + 2*model.Alpha2)
+
+		change := 0.0
+		for j := range coef {
+			change += math.Abs(next[j] - coef[j])
+		}
+		coef = next
+		if model.NIter > 1 && change < model.Tol {
+			converged = true
+			break
+		}
+	}
+
+	// Sigma = (alpha X'X + lambda I)^-1 = V (alpha S + lambda)^-1 V'
+	model.Sigma = make([][]float64, p)
+	logDetSigma := 0.0
+	for a := range model.Sigma {
+		model.Sigma[a] = make([]float64, p)
+	}
+	for k, v := range s {
+		d := 1 / (alpha*v + lambda)
+		logDetSigma += math.Log(d)
+		for a := 0; a < p; a++ {
+			for b := 0; b < p; b++ {
+				model.Sigma[a][b] += d * V[a][k] * V[b][k]
+			}
+		}
+	}
+
+	model.Coef, model.Alpha, model.Lambda = coef, alpha, lambda
+	model.Intercept = yMean - matrix.VecDot(xMean, coef)
+	model.xMean = xMean
+	model.LogEvidence = model.Lambda1*math.Log(lambda) - model.Lambda2*lambda +
+		model.Alpha1*math.Log(alpha) - model.Alpha2*alpha +
+		0.5*(float64(p)*math.Log(lambda)+n*math.Log(alpha)-alpha*rss-
+			lambda*sumSquares(coef)+logDetSigma-n*math.Log(2*math.Pi))
+
+	if !converged {
+		return errors.New("evidence maximization did not converge")
+	}
+	return nil
+}
+
+// predict the posterior mean response for samples (X)
+func (model BayesianRidge) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+/*
+ predictive distribution of the responses for samples (X)
+
+ returns
+ -------
+   (mean, variance) where variance = 1/alpha + x'Sigma x combines the
+   noise with the uncertainty in the coefficients
+*/
+func (model BayesianRidge) PredictWithVariance(X [][]float64) ([]float64, []float64) {
+	return model.Predict(X), predictiveVariance(X, model.xMean, model.Sigma, model.Alpha)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model BayesianRidge) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+/*
+ automatic relevance determination regression: Bayesian ridge with a
+ separate prior precision lambda_j for each coefficient. Evidence
+ maximization drives the precision of irrelevant features to infinity,
+ so features whose lambda_j exceeds ThresholdLambda are pruned (their
+ coefficients fixed at zero), giving sparse models.
+*/
+type ARDRegression struct {
+	FitIntercept    bool        // whether to fit an intercept term
+	Tol             float64     // total coefficient change at convergence
+	MaxIter         int         // maximum number of evidence updates
+	Alpha1          float64     // shape of the Gamma hyperprior on alpha
+	Alpha2          float64     // rate of the Gamma hyperprior on alpha
+	Lambda1         float64     // shape of the Gamma hyperprior on each lambda
+	Lambda2         float64     // rate of the Gamma hyperprior on each lambda
+	ThresholdLambda float64     // prune features with a larger precision
+	Coef            []float64   // posterior mean of the coefficients
+	Intercept       float64     // fitted intercept, 0 unless FitIntercept
+	Alpha           float64     // estimated noise precision
+	Lambda          []float64   // estimated precision of each coefficient
+	Sigma           [][]float64 // posterior covariance, zero for pruned features
+	NIter           int         // evidence updates used by the last fit
+	xMean           []float64   // feature means the model was centered on
+}
+
+/*
+ ARD regression constructor
+
+ arguments
+ ---------
+   fitIntercept: whether to fit an intercept
+*/
+func NewARDRegression(fitIntercept bool) *ARDRegression {
+	model := new(ARDRegression)
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-3
+	model.MaxIter = 300
+	model.Alpha1, model.Alpha2 = 1e-6, 1e-6
+	model.Lambda1, model.Lambda2 = 1e-6, 1e-6
+	model.ThresholdLambda = 1e4
+	return model
+}
+
+// fit the model with samples (X) and responses (y)
+func (model *ARDRegression) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	Xc, yc, xMean, yMean := center(X, y, model.FitIntercept)
+	n, p := float64(len(Xc)), len(Xc[0])
+	XtX := matrix.MatMultTrans(Xc, Xc)
+	Xty := matrix.VecMultTrans(Xc, yc)
+
+	alpha := 1 / math.Max(variance(yc), 1e-12)
+	lambda := make([]float64, p)
+	for j := range lambda {
+		lambda[j] = 1
+	}
+	coef := make([]float64, p)
+	var Sigma [][]float64
+
+	converged := false
+	for model.NIter = 1; model.NIter <= model.MaxIter; model.NIter++ {
+		var keep []int
+		for j := range lambda {
+			if lambda[j] < model.ThresholdLambda {
+				keep = append(keep, j)
+			}
+		}
+
+		// Sigma = (alpha X'X + diag(lambda))^-1 over the kept features
+		A := make([][]float64, len(keep))
+		b := make([]float64, len(keep))
+		for a, j := range keep {
+			A[a] = make([]float64, len(keep))
+			for c, k := range keep {
+				A[a][c] = alpha * XtX[j][k]
+			}
+			A[a][a] += lambda[j]
+			b[a] = alpha * Xty[j]
+		}
+
+		next := make([]float64, p)
+		gamma := make([]float64, p)
+		Sigma = make([][]float64, p)
+		for j := range Sigma {
+			Sigma[j] = make([]float64, p)
+		}
+		if len(keep) > 0 {
+			L, err := matrix.Cholesky(A)
+			if err != nil {
+				return err
+			}
+			S := matrix.CholeskyInverse(L)
+			mean := matrix.VecMult(S, b)
+			for a, j := range keep {
+				next[j] = mean[a]
+				gamma[j] = 1 - lambda[j]*S[a][a]
+				for c, k := range keep {
+					Sigma[j][k] = S[a][c]
+				}
+			}
+		}
+
+		rss := sumSquares(matrix.VecSub(yc, matrix.VecMult(Xc, next)))
+		totalGamma := 0.0
+		for _, j := range keep {
+			lambda[j] = (gamma[j] + 2*model.Lambda1) / (next[j]*next[j] + 2*model.Lambda2)
+			totalGamma += gamma[j]
+		}
+		alpha = (n - totalGamma + 2*model.Alpha1) / (rss + 2*model.Alpha2)
+
+		change := 0.0
+		for j := range coef {
+			change += math.Abs(next[j] - coef[j])
+		}
+		coef = next
+		if model.NIter > 1 && change < model.Tol {
+			converged = true
+			break
+		}
+	}
+
+	// coefficients pruned on the last update are zero, and so is their covariance
+	for j := range lambda {
+		if lambda[j] >= model.ThresholdLambda {
+			coef[j] = 0
+			for k := range Sigma {
+				Sigma[j][k], Sigma[k][j] = 0, 0
+			}
+		}
+	}
+
+	model.Coef, model.Alpha, model.Lambda, model.Sigma = coef, alpha, lambda, Sigma
+	model.Intercept = yMean - matrix.VecDot(xMean, coef)
+	model.xMean = xMean
+
+	if !converged {
+		return errors.New("evidence maximization did not converge")
+	}
+	return nil
+}
+
+// predict the posterior mean response for samples (X)
+func (model ARDRegression) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+/*
+ predictive distribution of the responses for samples (X)
+
+ returns
+ -------
+   (mean, variance) as for BayesianRidge
+*/
+func (model ARDRegression) PredictWithVariance(X [][]float64) ([]float64, []float64) {
+	return model.Predict(X), predictiveVariance(X, model.xMean, model.Sigma, model.Alpha)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model ARDRegression) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+// 1/alpha + (x - xMean)' Sigma (x - xMean) for each sample
+func predictiveVariance(X [][]float64, xMean []float64, Sigma [][]float64, alpha float64) []float64 {
+	v := make([]float64, len(X))
+	for i := range X {
+		xc := matrix.VecSub(X[i], xMean)
+		v[i] = 1/alpha + matrix.VecDot(xc, matrix.VecMult(Sigma, xc))
+	}
+	return v
+}
+
+func sumSquares(x []float64) float64 {
+	return matrix.VecDot(x, x)
+}
+
+func variance(x []float64) float64 {
+	mean := 0.0
+	for _, v := range x {
+		mean += v / float64(len(x))
+	}
+	ss := 0.0
+	for _, v := range x {
+		ss += (v - mean) * (v - mean)
+	}
+	return ss / float64(len(x))
+}
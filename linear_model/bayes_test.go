@@ -0,0 +1,183 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/emef/go.ml/matrix"
+)
+
+func TestBayesianRidge(t *testing.T) {
+	noise := 0.5
+	X, y, coef := randomProblem(200, 4, noise)
+
+	model := NewBayesianRidge(true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	// within 5 standard errors, about noise / sqrt(n) for unit features
+	for j := range coef {
+		if math.Abs(model.Coef[j]-coef[j]) > 5*noise/math.Sqrt(200) {
+			t.Errorf("coef %v, expected %v", model.Coef, coef)
+		}
+	}
+	// the noise variance estimate has a relative standard error of about
+	// sqrt(2 / (n - p - 1)); its chi-square distribution is skewed, so
+	// allow 5 standard errors
+	variance := noise * noise
+	if se := math.Sqrt(2.0 / (200 - 4 - 1)); math.Abs(1/model.Alpha-variance)/variance > 5*se {
+		t.Errorf("noise precision %.3f, expected %.3f", model.Alpha, 1/variance)
+	}
+
+	// the posterior mean is ridge regression with penalty lambda / alpha
+	ridge := NewRidge(model.Lambda/model.Alpha, true)
+	ridge.Fit(X, y)
+	for j := range coef {
+		if math.Abs(model.Coef[j]-ridge.Coef[j]) > 1e-3 {
+			t.Errorf("posterior mean %v, ridge %v", model.Coef, ridge.Coef)
+		}
+	}
+
+	// the predictive variance is at least the noise variance, and grows
+	// away from the training data
+	near := [][]float64{make([]float64, 4)}
+	far := [][]float64{{20, -20, 20, -20}}
+	_, vNear := model.PredictWithVariance(near)
+	_, vFar := model.PredictWithVariance(far)
+	if vNear[0] < 1/model.Alpha || vFar[0] <= vNear[0] {
+		t.Errorf("predictive variance %.4f near the data, %.4f far away, noise %.4f",
+			vNear[0], vFar[0], 1/model.Alpha)
+	}
+
+	// fresh responses fall within 2 predictive standard deviations as
+	// often as the true noise implies, within 5 standard errors
+	rng := rand.New(rand.NewSource(3))
+	m := 2000
+	Xt, yt, truth := make([][]float64, m), make([]float64, m), make([]float64, m)
+	for i := range Xt {
+		Xt[i] = make([]float64, 4)
+		for j := range Xt[i] {
+			Xt[i][j] = rng.NormFloat64()
+		}
+		truth[i] = predictLinear(Xt[i:i+1], coef, 1)[0]
+		yt[i] = truth[i] + noise*rng.NormFloat64()
+	}
+	mean, v := model.PredictWithVariance(Xt)
+	inside, expected, varInside := 0.0, 0.0, 0.0
+	for i := range yt {
+		if math.Abs(yt[i]-mean[i]) < 2*math.Sqrt(v[i]) {
+			inside++
+		}
+		d, s := mean[i]-truth[i], 2*math.Sqrt(v[i])
+		p := normalCDF((s-d)/noise) - normalCDF((-s-d)/noise)
+		expected += p
+		varInside += p * (1 - p)
+	}
+	if math.Abs(inside-expected) > 5*math.Sqrt(varInside) {
+		t.Errorf("%.0f of %d test responses within 2 standard deviations, expected %.1f",
+			inside, m, expected)
+	}
+}
+
+func TestBayesianRidgeEvidence(t *testing.T) {
+	X, y, _ := randomProblem(100, 3, 1)
+
+	model := NewBayesianRidge(true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	alpha, lambda := model.Alpha, model.Lambda
+	evidence := logEvidence(X, y, alpha, lambda)
+	if math.Abs(evidence-model.LogEvidence) > 1e-3 {
+		t.Errorf("log evidence %.6f, computed directly %.6f", model.LogEvidence, evidence)
+	}
+
+	// the estimates are a maximum of the evidence
+	for _, scale := range []float64{0.8, 1.25} {
+		if e := logEvidence(X, y, alpha*scale, lambda); e > evidence {
+			t.Errorf("evidence %.4f at alpha x %.2f exceeds %.4f at the estimate", e, scale, evidence)
+		}
+		if e := logEvidence(X, y, alpha, lambda*scale); e > evidence {
+			t.Errorf("evidence %.4f at lambda x %.2f exceeds %.4f at the estimate", e, scale, evidence)
+		}
+	}
+}
+
+// log N(yc | 0, I/alpha + Xc Xc'/lambda), computed directly
+func logEvidence(X [][]float64, y []float64, alpha, lambda float64) float64 {
+	Xc, yc, _, _ := center(X, y, true)
+	n := len(Xc)
+	C := make([][]float64, n)
+	for i := range C {
+		C[i] = make([]float64, n)
+		for j := range C {
+			C[i][j] = matrix.VecDot(Xc[i], Xc[j]) / lambda
+		}
+		C[i][i] += 1 / alpha
+	}
+
+	L, _ := matrix.Cholesky(C)
+	z := matrix.ForwardSubstitution(L, yc)
+	logDet := 0.0
+	for i := range L {
+		logDet += 2 * math.Log(L[i][i])
+	}
+	return -0.5 * (matrix.VecDot(z, z) + logDet + float64(n)*math.Log(2*math.Pi))
+}
+
+func TestARDRegression(t *testing.T) {
+	// only 3 of 10 features matter
+	rng := rand.New(rand.NewSource(7))
+	n, p := 200, 10
+	coef := []float64{2, 0, 0, -3, 0, 0, 0, 1.5, 0, 0}
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = make([]float64, p)
+		for j := range X[i] {
+			X[i][j] = rng.NormFloat64()
+		}
+		y[i] = 1 + predictLinear(X[i:i+1], coef, 0)[0] + 0.3*rng.NormFloat64()
+	}
+
+	model := NewARDRegression(true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := range coef {
+		if coef[j] == 0 && math.Abs(model.Coef[j]) > 0.1 {
+			t.Errorf("irrelevant feature %d has coefficient %.4f", j, model.Coef[j])
+		}
+		if coef[j] != 0 && math.Abs(model.Coef[j]-coef[j]) > 0.1 {
+			t.Errorf("feature %d coefficient %.4f, expected %.1f", j, model.Coef[j], coef[j])
+		}
+	}
+	if math.Abs(model.Intercept-1) > 0.1 {
+		t.Errorf("intercept %.4f, expected 1", model.Intercept)
+	}
+
+	// some irrelevant features are pruned outright, relevant ones never
+	pruned := 0
+	for j := range coef {
+		if model.Coef[j] == 0 {
+			pruned++
+			if coef[j] != 0 {
+				t.Errorf("relevant feature %d was pruned", j)
+			}
+		}
+	}
+	if pruned == 0 {
+		t.Errorf("no irrelevant features pruned, lambda %v", model.Lambda)
+	}
+
+	mean, v := model.PredictWithVariance(X[:5])
+	for i := range mean {
+		if v[i] < 1/model.Alpha || math.Abs(mean[i]-y[i]) > 4*math.Sqrt(v[i]) {
+			t.Errorf("sample %d: prediction %.3f +/- %.3f, response %.3f", i, mean[i], math.Sqrt(v[i]), y[i])
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+/*
+ linear regression with combined L1 and L2 penalties, minimizing
+
+     1/(2n) ||y - Xw||^2 + alpha * l1Ratio * ||w||_1
+                         + alpha * (1 - l1Ratio) / 2 * ||w||^2
+*/
+type ElasticNet struct {
+	Alpha        float64   // overall regularization strength
+	L1Ratio      float64   // mix of L1 (1) and L2 (0) penalties
+	Coef         []float64 // fitted coefficients, one per feature
+	Intercept    float64   // fitted (unpenalized) intercept
+	FitIntercept bool      // whether to fit an intercept term
+	WarmStart    bool      // start from the current Coef when refitting
+	Tol          float64   // duality gap tolerance, relative to ||y||^2
+	MaxIter      int       // maximum number of coordinate descent sweeps
+	NIter        int       // sweeps used by the last fit
+	DualityGap   float64   // duality gap at the end of the last fit
+}
+
+// linear regression with an L1 penalty, an ElasticNet with L1Ratio = 1
+type Lasso struct {
+	ElasticNet
+}
+
+/*
+ elastic net constructor
+
+ arguments
+ ---------
+   alpha:        overall regularization strength
+   l1Ratio:      in [0, 1], 1 is the lasso and 0 is ridge regression
+   fitIntercept: whether to fit an (unpenalized) intercept
+*/
+func NewElasticNet(alpha, l1Ratio float64, fitIntercept bool) *ElasticNet {
+	model := new(ElasticNet)
+	model.Alpha = alpha
+	model.L1Ratio = l1Ratio
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-4
+	model.MaxIter = 1000
+	return model
+}
+
+// lasso constructor, see NewElasticNet
+func NewLasso(alpha float64, fitIntercept bool) *Lasso {
+	return &Lasso{*NewElasticNet(alpha, 1, fitIntercept)}
+}
+
+// fit the model with cyclic coordinate descent
+func (model *ElasticNet) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+	if model.Alpha < 0 || model.L1Ratio < 0 || model.L1Ratio > 1 {
+		return errors.New("alpha must be >= 0 and l1Ratio in [0, 1]")
+	}
+
+	Xc, yc, xMean, yMean := center(X, y, model.FitIntercept)
+
+	if !model.WarmStart || len(model.Coef) != len(X[0]) {
+		model.Coef = make([]float64, len(X[0]))
+	}
+
+	model.NIter, model.DualityGap = coordinateDescent(Xc, yc, model.Coef,
+		model.Alpha*model.L1Ratio, model.Alpha*(1-model.L1Ratio),
+		model.Tol, model.MaxIter)
+	model.Intercept = yMean - matrix.VecDot(xMean, model.Coef)
+
+	if model.DualityGap > model.Tol*matrix.VecDot(yc, yc) {
+		return errors.New("coordinate descent did not converge")
+	}
+	return nil
+}
+
+// predict responses for samples (X)
+func (model ElasticNet) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model ElasticNet) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+/*
+ Computes the elastic net regularization path, fitting each alpha in
+ decreasing order warm started from the previous solution.
+
+ arguments
+ ---------
+   X, y:         training samples and responses
+   l1Ratio:      mix of L1 and L2 penalties, see ElasticNet
+   alphas:       regularization strengths; if nil, 100 log-spaced values
+                 from the smallest alpha giving all zero coefficients
+                 down to 1e-3 times that
+   fitIntercept: whether to fit an (unpenalized) intercept
+
+ returns
+ -------
+   (alphas, coefs, intercepts) where coefs[k] and intercepts[k] are the
+   fitted model for alphas[k], sorted by decreasing alpha
+*/
+func ElasticNetPath(X [][]float64, y []float64, l1Ratio float64, alphas []float64, fitIntercept bool) ([]float64, [][]float64, []float64, error) {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if alphas == nil {
+		alphas = alphaGrid(X, y, l1Ratio, 100, 1e-3, fitIntercept)
+	} else {
+		alphas = append([]float64{}, alphas...)
+		sort.Sort(sort.Reverse(sort.Float64Slice(alphas)))
+	}
+
+	model := NewElasticNet(0, l1Ratio, fitIntercept)
+	model.WarmStart = true
+
+	coefs := make([][]float64, len(alphas))
+	intercepts := make([]float64, len(alphas))
+	for k, alpha := range alphas {
+		model.Alpha = alpha
+		if err := model.Fit(X, y); err != nil {
+			return alphas, coefs, intercepts, err
+		}
+		coefs[k] = append([]float64{}, model.Coef...)
+		intercepts[k] = model.Intercept
+	}
+
+	return alphas, coefs, intercepts, nil
+}
+
+/*
+ log-spaced alphas from alphaMax = max |X'y| / (n * l1Ratio), the smallest
+ alpha for which every coefficient is zero, down to eps * alphaMax
+*/
+func alphaGrid(X [][]float64, y []float64, l1Ratio float64, nAlphas int, eps float64, fitIntercept bool) []float64 {
+	Xc, yc, _, _ := center(X, y, fitIntercept)
+	Xy := matrix.VecMultTrans(Xc, yc)
+
+	alphaMax := 0.0
+	for _, v := range Xy {
+		alphaMax = math.Max(alphaMax, math.Abs(v))
+	}
+	alphaMax /= float64(len(X)) * math.Max(l1Ratio, 1e-3)
+
+	alphas := make([]float64, nAlphas)
+	for k := range alphas {
+		alphas[k] = alphaMax * math.Pow(eps, float64(k)/float64(nAlphas-1))
+	}
+	return alphas
+}
+
+/*
+ Minimizes 1/(2n) ||y - Xw||^2 + l1 ||w||_1 + l2/2 ||w||^2 in place by
+ cyclic coordinate descent.
+
+ The duality gap is only evaluated once a sweep changes no coefficient
+ by more than tol relative to the largest one, and the descent stops when
+ the gap (on the n-scaled problem) falls below tol * ||y||^2.
+
+ returns
+ -------
+   (sweeps, gap)
+*/
+func coordinateDescent(X [][]float64, y []float64, w []float64, l1, l2, tol float64, maxIter int) (int, float64) {
+	n, p := len(X), len(w)
+	N := float64(n)
+
+	colNorm := make([]float64, p)
+	for i := range X {
+		for j := 0; j < p; j++ {
+			colNorm[j] += X[i][j] * X[i][j]
+		}
+	}
+
+	// residual for the starting coefficients
+	R := matrix.VecSub(y, matrix.VecMult(X, w))
+
+	gap := math.Inf(1)
+	yNorm := matrix.VecDot(y, y)
+	iter := 0
+	for iter < maxIter {
+		iter++
+		maxChange, maxW := 0.0, 0.0
+
+		for j := 0; j < p; j++ {
+			if colNorm[j] == 0 {
+				continue
+			}
+			old := w[j]
+
+			// rho = X_j'(r + X_j w_j) / n
+			rho := 0.0
+			for i := 0; i < n; i++ {
+				rho += X[i][j] * R[i]
+			}
+			rho = rho/N + old*colNorm[j]/N
+
+			w[j] = softThreshold(rho, l1) / (colNorm[j]/N + l2)
+
+			if delta := w[j] - old; delta != 0 {
+				for i := 0; i < n; i++ {
+					R[i] -= X[i][j] * delta
+				}
+				maxChange = math.Max(maxChange, math.Abs(delta))
+			}
+			maxW = math.Max(maxW, math.Abs(w[j]))
+		}
+
+		if maxW == 0 || maxChange/maxW < tol || iter == maxIter {
+			gap = dualityGap(X, y, w, R, l1*N, l2*N)
+			if gap <= tol*yNorm {
+				break
+			}
+		}
+	}
+
+	return iter, gap
+}
+
+/*
+ duality gap of 1/2 ||y - Xw||^2 + alpha ||w||_1 + beta/2 ||w||^2 at w,
+ given the residual R = y - Xw
+*/
+func dualityGap(X [][]float64, y, w, R []float64, alpha, beta float64) float64 {
+	XtA := matrix.VecMultTrans(X, R)
+	dualNorm := 0.0
+	for j := range XtA {
+		XtA[j] -= beta * w[j]
+		dualNorm = math.Max(dualNorm, math.Abs(XtA[j]))
+	}
+
+	RNorm := matrix.VecDot(R, R)
+	wNorm := matrix.VecDot(w, w)
+
+	// scale the residual into the dual feasible set, which only the L1
+	// penalty constrains
+	scale := 1.0
+	gap := RNorm
+	if alpha > 0 && dualNorm > alpha {
+		scale = alpha / dualNorm
+		gap = 0.5 * (RNorm + RNorm*scale*scale)
+	}
+
+	l1Norm := 0.0
+	for _, v := range w {
+		l1Norm += math.Abs(v)
+	}
+
+	gap += alpha*l1Norm - scale*matrix.VecDot(R, y) + 0.5*beta*(1+scale*scale)*wNorm
+	return gap
+}
+
+func softThreshold(x, t float64) float64 {
+	switch {
+	case x > t:
+		return x - t
+	case x < -t:
+		return x + t
+	}
+	return 0
+}
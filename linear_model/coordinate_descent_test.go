@@ -0,0 +1,87 @@
+package linear_model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLassoOrthogonal(t *testing.T) {
+	// with orthogonal columns (X'X = nI) the lasso solution is the
+	// soft-thresholded OLS solution
+	X := [][]float64{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	y := []float64{3, 1, -1, -2.5}
+
+	ols := NewLinearRegression(false)
+	ols.Fit(X, y)
+
+	alpha := 0.3
+	model := NewLasso(alpha, false)
+	model.Tol = 1e-10
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := range ols.Coef {
+		expected := softThreshold(ols.Coef[j], alpha)
+		if math.Abs(model.Coef[j]-expected) > 1e-8 {
+			t.Errorf("lasso coef %v, expected soft threshold of %v", model.Coef, ols.Coef)
+		}
+	}
+}
+
+func TestElasticNetRidgeLimit(t *testing.T) {
+	X, y, _ := randomProblem(40, 4, 0.2)
+	alpha := 0.5
+
+	model := NewElasticNet(alpha, 0, true)
+	model.Tol = 1e-12
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1/(2n)||r||^2 + alpha/2 ||w||^2 is ridge with n * alpha
+	ridge := NewRidge(alpha*float64(len(X)), true)
+	ridge.Fit(X, y)
+
+	for j := range ridge.Coef {
+		if math.Abs(model.Coef[j]-ridge.Coef[j]) > 1e-6 {
+			t.Errorf("elastic net %v, ridge %v", model.Coef, ridge.Coef)
+			break
+		}
+	}
+	if math.Abs(model.Intercept-ridge.Intercept) > 1e-6 {
+		t.Errorf("intercepts differ: %.6f vs %.6f", model.Intercept, ridge.Intercept)
+	}
+}
+
+func TestElasticNetPath(t *testing.T) {
+	X, y, _ := randomProblem(60, 8, 0.5)
+
+	alphas, coefs, _, err := ElasticNetPath(X, y, 1, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k := 1; k < len(alphas); k++ {
+		if alphas[k] >= alphas[k-1] {
+			t.Fatalf("alphas not decreasing at %d", k)
+		}
+	}
+
+	nonZero := func(coef []float64) int {
+		count := 0
+		for _, c := range coef {
+			if c != 0 {
+				count++
+			}
+		}
+		return count
+	}
+
+	if nz := nonZero(coefs[0]); nz != 0 {
+		t.Errorf("%d non-zero coefficients at alpha max", nz)
+	}
+	if nz := nonZero(coefs[len(coefs)-1]); nz != 8 {
+		t.Errorf("%d non-zero coefficients at smallest alpha, expected 8", nz)
+	}
+}
@@ -0,0 +1,129 @@
+package linear_model
+
+import (
+	"math"
+
+	"github.com/emef/go.ml/optimization"
+)
+
+// P(Z <= z) for a standard normal Z
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// z such that P(Z <= z) = p for a standard normal Z
+func normalQuantile(p float64) float64 {
+	return -math.Sqrt2 * math.Erfcinv(2*p)
+}
+
+// P(T <= t) for a Student's t variable with df degrees of freedom
+func studentTCDF(t, df float64) float64 {
+	tail := 0.5 * regularizedBeta(df/(df+t*t), df/2, 0.5)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// t such that P(T <= t) = p for a Student's t variable with df degrees of freedom
+func studentTQuantile(p, df float64) float64 {
+	f := func(t float64) float64 { return studentTCDF(t, df) - p }
+
+	// expand a bracket around the normal quantile until it holds a root
+	z := normalQuantile(p)
+	width := math.Max(math.Abs(z), 1)
+	for f(z-width)*f(z+width) > 0 && width < 1e12 {
+		width *= 2
+	}
+
+	t, _ := optimization.BrentRoot(f, z-width, z+width, 1e-12)
+	return t
+}
+
+// P(F > f) for an F variable with (d1, d2) degrees of freedom
+func fSurvival(f, d1, d2 float64) float64 {
+	if f <= 0 {
+		return 1
+	}
+	return regularizedBeta(d2/(d2+d1*f), d2/2, d1/2)
+}
+
+// P(X > x) for a chi-squared variable with 2 degrees of freedom
+func chi2Survival2(x float64) float64 {
+	return math.Exp(-x / 2)
+}
+
+/*
+ regularized incomplete beta function I_x(a, b), evaluated with the
+ continued fraction expansion (Numerical Recipes, 6.4)
+*/
+func regularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+
+	// the continued fraction converges quickly for x < (a+1)/(a+b+2)
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+
+	qab, qap, qam := a+b, a+1, a-1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= 300; m++ {
+		M := float64(m)
+		m2 := 2 * M
+
+		// even step
+		aa := M * (b - M) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// odd step
+		aa = -(a + M) * (qab + M) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < 1e-15 {
+			break
+		}
+	}
+
+	return h
+}
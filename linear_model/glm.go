@@ -0,0 +1,300 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+)
+
+// link function g relating the mean to the linear predictor, eta = g(mu)
+type Link interface {
+	Link(mu float64) float64     // g(mu)
+	Inverse(eta float64) float64 // g^-1(eta)
+	Deriv(mu float64) float64    // g'(mu)
+}
+
+// exponential family distribution of the responses
+type Family interface {
+	Variance(mu float64) float64      // variance function V(mu)
+	Deviance(y, mu float64) float64   // unit deviance d(y, mu)
+	StartMu(y, yMean float64) float64 // initial mean for IRLS
+	DefaultLink() Link                // link used when none is given
+	EstimateDispersion() bool         // false if the dispersion is fixed at 1
+}
+
+type IdentityLink struct{}
+type LogLink struct{}
+type LogitLink struct{}
+type InverseLink struct{}
+
+func (IdentityLink) Link(mu float64) float64     { return mu }
+func (IdentityLink) Inverse(eta float64) float64 { return eta }
+func (IdentityLink) Deriv(mu float64) float64    { return 1 }
+
+func (LogLink) Link(mu float64) float64     { return math.Log(mu) }
+func (LogLink) Inverse(eta float64) float64 { return math.Exp(eta) }
+func (LogLink) Deriv(mu float64) float64    { return 1 / mu }
+
+func (LogitLink) Link(mu float64) float64     { return math.Log(mu / (1 - mu)) }
+func (LogitLink) Inverse(eta float64) float64 { return sigmoid(eta) }
+func (LogitLink) Deriv(mu float64) float64    { return 1 / (mu * (1 - mu)) }
+
+func (InverseLink) Link(mu float64) float64     { return 1 / mu }
+func (InverseLink) Inverse(eta float64) float64 { return 1 / eta }
+func (InverseLink) Deriv(mu float64) float64    { return -1 / (mu * mu) }
+
+// normal responses, V(mu) = 1
+type Gaussian struct{}
+
+// counts, V(mu) = mu
+type Poisson struct{}
+
+// proportions in [0, 1], V(mu) = mu (1 - mu)
+type Binomial struct{}
+
+// positive continuous responses, V(mu) = mu^2; defaults to the log link
+type Gamma struct{}
+
+// compound Poisson-Gamma and friends, V(mu) = mu^Power; defaults to the log link
+type Tweedie struct {
+	Power float64 // 0 Gaussian, 1 Poisson, (1, 2) compound Poisson-Gamma, 2 Gamma
+}
+
+func (Gaussian) Variance(mu float64) float64      { return 1 }
+func (Gaussian) Deviance(y, mu float64) float64   { return (y - mu) * (y - mu) }
+func (Gaussian) StartMu(y, yMean float64) float64 { return y }
+func (Gaussian) DefaultLink() Link                { return IdentityLink{} }
+func (Gaussian) EstimateDispersion() bool         { return true }
+
+func (Poisson) Variance(mu float64) float64 { return mu }
+func (Poisson) Deviance(y, mu float64) float64 {
+	return 2 * (xlogy(y, y/mu) - (y - mu))
+}
+func (Poisson) StartMu(y, yMean float64) float64 { return (y + yMean) / 2 }
+func (Poisson) DefaultLink() Link                { return LogLink{} }
+func (Poisson) EstimateDispersion() bool         { return false }
+
+func (Binomial) Variance(mu float64) float64 { return mu * (1 - mu) }
+func (Binomial) Deviance(y, mu float64) float64 {
+	return 2 * (xlogy(y, y/mu) + xlogy(1-y, (1-y)/(1-mu)))
+}
+func (Binomial) StartMu(y, yMean float64) float64 { return (y + 0.5) / 2 }
+func (Binomial) DefaultLink() Link                { return LogitLink{} }
+func (Binomial) EstimateDispersion() bool         { return false }
+
+func (Gamma) Variance(mu float64) float64 { return mu * mu }
+func (Gamma) Deviance(y, mu float64) float64 {
+	return 2 * (-math.Log(y/mu) + (y-mu)/mu)
+}
+func (Gamma) StartMu(y, yMean float64) float64 { return (y + yMean) / 2 }
+func (Gamma) DefaultLink() Link                { return LogLink{} }
+func (Gamma) EstimateDispersion() bool         { return true }
+
+func (t Tweedie) Variance(mu float64) float64 { return math.Pow(mu, t.Power) }
+func (t Tweedie) Deviance(y, mu float64) float64 {
+	p := t.Power
+	switch p {
+	case 0:
+		return Gaussian{}.Deviance(y, mu)
+	case 1:
+		return Poisson{}.Deviance(y, mu)
+	case 2:
+		return Gamma{}.Deviance(y, mu)
+	}
+	return 2 * (math.Pow(math.Max(y, 0), 2-p)/((1-p)*(2-p)) -
+		y*math.Pow(mu, 1-p)/(1-p) + math.Pow(mu, 2-p)/(2-p))
+}
+func (t Tweedie) StartMu(y, yMean float64) float64 { return (y + yMean) / 2 }
+func (t Tweedie) DefaultLink() Link                { return LogLink{} }
+func (t Tweedie) EstimateDispersion() bool         { return t.Power != 1 }
+
+// generalized linear model, E[y | x] = g^-1(x'coef + intercept)
+type GLM struct {
+	Family       Family
+	Link         Link
+	FitIntercept bool
+	Tol          float64 // relative change in deviance at convergence
+	MaxIter      int     // maximum number of IRLS iterations
+
+	Coef         []float64 // fitted coefficients, one per feature
+	Intercept    float64   // fitted intercept
+	StdErr       []float64 // standard errors, intercept first if fitted
+	Deviance     float64   // residual deviance of the fit
+	NullDeviance float64   // deviance of the intercept-only model
+	Dispersion   float64   // Pearson estimate, or 1 for Poisson/Binomial
+	NIter        int       // IRLS iterations used by the last fit
+}
+
+/*
+ generalized linear model constructor
+
+ arguments
+ ---------
+   family:       distribution of the responses, e.g. Poisson{}
+   link:         link function, or nil for the family's default
+   fitIntercept: whether to fit an intercept
+*/
+func NewGLM(family Family, link Link, fitIntercept bool) *GLM {
+	if link == nil {
+		link = family.DefaultLink()
+	}
+
+	model := new(GLM)
+	model.Family = family
+	model.Link = link
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-8
+	model.MaxIter = 100
+	return model
+}
+
+/*
+ fit the model by iteratively reweighted least squares: each iteration
+ solves the weighted least squares problem for the working responses
+
+     z_i = eta_i + (y_i - mu_i) g'(mu_i)
+     w_i = 1 / (g'(mu_i)^2 V(mu_i))
+*/
+func (model *GLM) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	Z := withOnes(X, model.FitIntercept)
+	n, k := len(Z), len(Z[0])
+	family, link := model.Family, model.Link
+
+	yMean := 0.0
+	for _, v := range y {
+		yMean += v / float64(n)
+	}
+
+	mu := make([]float64, n)
+	eta := make([]float64, n)
+	for i := range y {
+		mu[i] = family.StartMu(y[i], yMean)
+		eta[i] = link.Link(mu[i])
+	}
+
+	deviance := func(mu []float64) float64 {
+		d := 0.0
+		for i := range y {
+			d += family.Deviance(y[i], mu[i])
+		}
+		return d
+	}
+
+	dev := deviance(mu)
+	var params []float64
+	var L [][]float64
+	converged := false
+
+	for model.NIter = 0; model.NIter < model.MaxIter && !converged; model.NIter++ {
+		z := make([]float64, n)
+		w := make([]float64, n)
+		for i := range y {
+			d := link.Deriv(mu[i])
+			z[i] = eta[i] + (y[i]-mu[i])*d
+			w[i] = 1 / (d * d * family.Variance(mu[i]))
+		}
+
+		// solve (Z'WZ) params = Z'Wz
+		A := make([][]float64, k)
+		for a := range A {
+			A[a] = make([]float64, k)
+		}
+		b := make([]float64, k)
+		for i := range Z {
+			for a := 0; a < k; a++ {
+				b[a] += w[i] * Z[i][a] * z[i]
+				for c := 0; c <= a; c++ {
+					A[a][c] += w[i] * Z[i][a] * Z[i][c]
+				}
+			}
+		}
+
+		var err error
+		if L, err = matrix.Cholesky(A); err != nil {
+			return errors.New("weighted design matrix is singular")
+		}
+		next := matrix.CholeskySolve(L, b)
+
+		// halve the step while the deviance is undefined
+		for halving := 0; ; halving++ {
+			for i := range Z {
+				eta[i] = matrix.VecDot(Z[i], next)
+				mu[i] = link.Inverse(eta[i])
+			}
+			newDev := deviance(mu)
+			if !math.IsNaN(newDev) && !math.IsInf(newDev, 0) {
+				converged = math.Abs(newDev-dev)/(math.Abs(newDev)+0.1) < model.Tol
+				dev = newDev
+				break
+			}
+			if params == nil || halving == 30 {
+				return errors.New("deviance is not finite")
+			}
+			next = matrix.VecScale(0.5, matrix.VecAdd(next, params))
+		}
+		params = next
+	}
+
+	if model.FitIntercept {
+		model.Intercept, model.Coef = params[0], params[1:]
+	} else {
+		model.Intercept, model.Coef = 0, params
+	}
+	model.Deviance = dev
+
+	// Pearson chi^2 / (n - k) estimate of the dispersion
+	model.Dispersion = 1
+	if family.EstimateDispersion() {
+		chi2 := 0.0
+		for i := range y {
+			chi2 += (y[i] - mu[i]) * (y[i] - mu[i]) / family.Variance(mu[i])
+		}
+		model.Dispersion = chi2 / float64(n-k)
+	}
+
+	// cov(params) = dispersion (Z'WZ)^-1, using the last weights
+	cov := matrix.CholeskyInverse(L)
+	model.StdErr = make([]float64, k)
+	for j := range cov {
+		model.StdErr[j] = math.Sqrt(model.Dispersion * cov[j][j])
+	}
+
+	// the intercept-only model predicts the mean everywhere (without an
+	// intercept the null model predicts g^-1(0))
+	nullMu := link.Inverse(0)
+	if model.FitIntercept {
+		nullMu = yMean
+	}
+	model.NullDeviance = 0
+	for _, v := range y {
+		model.NullDeviance += family.Deviance(v, nullMu)
+	}
+
+	if !converged {
+		return errors.New("IRLS did not converge")
+	}
+	return nil
+}
+
+// predict the mean response g^-1(x'coef + intercept) for samples (X)
+func (model GLM) Predict(X [][]float64) []float64 {
+	mu := predictLinear(X, model.Coef, model.Intercept)
+	for i := range mu {
+		mu[i] = model.Link.Inverse(mu[i])
+	}
+	return mu
+}
+
+// x log(y), defined as 0 when x = 0
+func xlogy(x, y float64) float64 {
+	if x == 0 {
+		return 0
+	}
+	return x * math.Log(y)
+}
@@ -0,0 +1,137 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/matrix"
+)
+
+// Knuth's method, fine for small means
+func poissonSample(rng *rand.Rand, lambda float64) float64 {
+	L, k, p := math.Exp(-lambda), 0.0, 1.0
+	for {
+		p *= rng.Float64()
+		if p <= L {
+			return k
+		}
+		k++
+	}
+}
+
+func TestGLMGaussianIsOLS(t *testing.T) {
+	X, y, _ := randomProblem(40, 3, 0.5)
+
+	ols := NewLinearRegression(true)
+	ols.Fit(X, y)
+	summary, _ := ols.Summary(X, y, NonRobust, 0.05)
+
+	glm := NewGLM(Gaussian{}, nil, true)
+	if err := glm.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := range ols.Coef {
+		if math.Abs(glm.Coef[j]-ols.Coef[j]) > 1e-9 {
+			t.Errorf("gaussian GLM %v, OLS %v", glm.Coef, ols.Coef)
+		}
+	}
+	for j := range summary.StdErr {
+		if math.Abs(glm.StdErr[j]-summary.StdErr[j]) > 1e-9 {
+			t.Errorf("gaussian GLM std err %v, OLS %v", glm.StdErr, summary.StdErr)
+		}
+	}
+
+	rss := 0.0
+	for _, e := range summary.Residuals {
+		rss += e * e
+	}
+	if math.Abs(glm.Deviance-rss) > 1e-9 {
+		t.Errorf("deviance %.6f, expected RSS %.6f", glm.Deviance, rss)
+	}
+}
+
+func TestGLMPoisson(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	coef := []float64{0.5, -0.3}
+	n := 2000
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rng.NormFloat64(), rng.NormFloat64()}
+		y[i] = poissonSample(rng, math.Exp(1 + matrix.VecDot(X[i], coef)))
+	}
+
+	model := NewGLM(Poisson{}, nil, true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(model.Intercept-1) > 4*model.StdErr[0] {
+		t.Errorf("intercept %.3f (+/- %.3f), expected 1", model.Intercept, model.StdErr[0])
+	}
+	for j := range coef {
+		if math.Abs(model.Coef[j]-coef[j]) > 4*model.StdErr[j+1] {
+			t.Errorf("coef %v (+/- %v), expected %v", model.Coef, model.StdErr, coef)
+		}
+	}
+	if model.Dispersion != 1 || model.Deviance >= model.NullDeviance {
+		t.Errorf("dispersion %.3f, deviance %.1f, null deviance %.1f",
+			model.Dispersion, model.Deviance, model.NullDeviance)
+	}
+	for _, mu := range model.Predict(X[:5]) {
+		if mu <= 0 {
+			t.Errorf("poisson mean %.3f should be positive", mu)
+		}
+	}
+}
+
+func TestGLMBinomialIsLogistic(t *testing.T) {
+	X, y := randomClassification(300, []float64{1, -2}, 0.5)
+
+	glm := NewGLM(Binomial{}, nil, true)
+	if err := glm.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	logistic, _ := NewLogisticRegression(L2, 0, true)
+	logistic.Tol = 1e-10
+	logistic.Fit(X, y)
+
+	for j := range glm.Coef {
+		if math.Abs(glm.Coef[j]-logistic.Coef[j]) > 1e-5 {
+			t.Errorf("binomial GLM %v, logistic regression %v", glm.Coef, logistic.Coef)
+		}
+	}
+}
+
+func TestGLMGammaTweedie(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	n := 500
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rng.Float64()}
+		mu := math.Exp(0.5 + X[i][0])
+		// gamma with shape 4: sum of 4 exponentials
+		for s := 0; s < 4; s++ {
+			y[i] += rng.ExpFloat64() * mu / 4
+		}
+	}
+
+	for _, family := range []Family{Gamma{}, Tweedie{1.5}, Tweedie{2}} {
+		model := NewGLM(family, nil, true)
+		if err := model.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(model.Coef[0]-1) > 0.3 || math.Abs(model.Intercept-0.5) > 0.3 {
+			t.Errorf("%T: fit %.3f + %.3f x, expected 0.5 + x", family, model.Intercept, model.Coef[0])
+		}
+		if family == (Tweedie{2}) || family == (Gamma{}) {
+			// gamma dispersion is 1 / shape
+			if math.Abs(model.Dispersion-0.25) > 0.08 {
+				t.Errorf("%T: dispersion %.3f, expected 0.25", family, model.Dispersion)
+			}
+		}
+	}
+}
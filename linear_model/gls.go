@@ -0,0 +1,134 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+)
+
+/*
+ fit the model by weighted least squares, minimizing
+
+     sum_i w_i (y_i - x_i'coef - intercept)^2
+
+ arguments
+ ---------
+   X, y:    training samples and responses
+   weights: non-negative weight of each sample, e.g. 1 / variance, with
+            a positive sum
+
+ returns
+ -------
+   error for invalid weights, or matrix.ErrSingular if the samples with
+   positive weight don't determine the coefficients
+*/
+func (model *LinearRegression) FitWeighted(X [][]float64, y, weights []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+	if len(weights) != len(y) {
+		return errors.New("number of weights and responses differ")
+	}
+	total := 0.0
+	for _, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 1) {
+			return errors.New("weights must be non-negative and finite")
+		}
+		total += w
+	}
+	if total == 0 {
+		return errors.New("weights must not all be zero")
+	}
+
+	Xc, yc, xMean, yMean := weightedCenter(X, y, weights, model.FitIntercept)
+
+	// rescale rows by sqrt(w_i) and solve the ordinary problem
+	Xw := make([][]float64, len(Xc))
+	yw := make([]float64, len(yc))
+	for i := range Xc {
+		sw := math.Sqrt(weights[i])
+		Xw[i] = matrix.VecScale(sw, Xc[i])
+		yw[i] = sw * yc[i]
+	}
+
+	coef, err := solveNormal(Xw, yw)
+	if err != nil {
+		return err
+	}
+	model.Coef = coef
+	model.Intercept = yMean - matrix.VecDot(xMean, model.Coef)
+
+	return nil
+}
+
+/*
+ fit the model by generalized least squares, for errors with a known
+ covariance sigma (up to scale):
+
+     coef = (X' sigma^-1 X)^-1 X' sigma^-1 y
+
+ With sigma = LL' the problem is whitened by L^-1 and solved as OLS. An
+ intercept is fit as an explicit column of ones since whitening doesn't
+ preserve centering.
+
+ arguments
+ ---------
+   X, y:  training samples and responses
+   sigma: n x n error covariance, e.g. from AR1Covariance
+
+ returns
+ -------
+   error if sigma isn't positive definite, or matrix.ErrSingular if the
+   whitened design matrix doesn't determine the coefficients
+*/
+func (model *LinearRegression) FitGLS(X [][]float64, y []float64, sigma [][]float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+	if len(sigma) != len(y) {
+		return errors.New("covariance and responses differ in size")
+	}
+
+	L, err := matrix.Cholesky(sigma)
+	if err != nil {
+		return err
+	}
+
+	// whiten each column of the design matrix and the responses
+	Zt := matrix.Transpose(withOnes(X, model.FitIntercept))
+	for j := range Zt {
+		Zt[j] = matrix.ForwardSubstitution(L, Zt[j])
+	}
+	Zw := matrix.Transpose(Zt)
+	yw := matrix.ForwardSubstitution(L, y)
+
+	params, err := solveNormal(Zw, yw)
+	if err != nil {
+		return err
+	}
+
+	if model.FitIntercept {
+		model.Intercept, model.Coef = params[0], params[1:]
+	} else {
+		model.Intercept, model.Coef = 0, params
+	}
+
+	return nil
+}
+
+/*
+ covariance of a stationary AR(1) error process with unit variance,
+ sigma_ij = rho^|i - j|, for use with FitGLS
+*/
+func AR1Covariance(n int, rho float64) [][]float64 {
+	sigma := make([][]float64, n)
+	for i := range sigma {
+		sigma[i] = make([]float64, n)
+		for j := range sigma[i] {
+			sigma[i][j] = math.Pow(rho, math.Abs(float64(i-j)))
+		}
+	}
+	return sigma
+}
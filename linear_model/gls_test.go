@@ -0,0 +1,128 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/matrix"
+)
+
+func TestFitWeighted(t *testing.T) {
+	X, y, _ := randomProblem(30, 2, 0.3)
+
+	// integer weights are equivalent to repeating samples
+	weights := make([]float64, len(y))
+	var XRep [][]float64
+	var yRep []float64
+	for i := range y {
+		weights[i] = float64(1 + i%3)
+		for r := 0; r < 1+i%3; r++ {
+			XRep = append(XRep, X[i])
+			yRep = append(yRep, y[i])
+		}
+	}
+
+	weighted := NewLinearRegression(true)
+	if err := weighted.FitWeighted(X, y, weights); err != nil {
+		t.Fatal(err)
+	}
+	repeated := NewLinearRegression(true)
+	repeated.Fit(XRep, yRep)
+
+	for j := range weighted.Coef {
+		if math.Abs(weighted.Coef[j]-repeated.Coef[j]) > 1e-9 {
+			t.Errorf("weighted %v, repeated %v", weighted.Coef, repeated.Coef)
+		}
+	}
+	if math.Abs(weighted.Intercept-repeated.Intercept) > 1e-9 {
+		t.Errorf("intercepts differ: %.6f vs %.6f", weighted.Intercept, repeated.Intercept)
+	}
+
+	if err := weighted.FitWeighted(X, y, weights[1:]); err == nil {
+		t.Error("expected error for mismatched weights")
+	}
+}
+
+func TestFitWeightedErrors(t *testing.T) {
+	X, y, _ := randomProblem(5, 2, 0.3)
+	model := NewLinearRegression(true)
+
+	for _, weights := range [][]float64{
+		{1, 1, -1, 1, 1},
+		{1, 1, math.NaN(), 1, 1},
+		{1, 1, math.Inf(1), 1, 1},
+		{0, 0, 0, 0, 0},
+		{1, 1, 1, 1},
+	} {
+		if err := model.FitWeighted(X, y, weights); err == nil {
+			t.Errorf("expected an error for weights %v", weights)
+		}
+	}
+
+	// two samples with positive weight can't determine two slopes and an intercept
+	if err := model.FitWeighted(X, y, []float64{1, 1, 0, 0, 0}); err == nil {
+		t.Errorf("expected an error when the weighted samples are singular")
+	}
+	for _, c := range model.Coef {
+		if math.IsNaN(c) {
+			t.Errorf("NaN coefficients %v", model.Coef)
+		}
+	}
+}
+
+func TestFitGLS(t *testing.T) {
+	X, y, _ := randomProblem(25, 2, 0.3)
+
+	// identity covariance is ordinary least squares
+	ols := NewLinearRegression(true)
+	ols.Fit(X, y)
+	gls := NewLinearRegression(true)
+	if err := gls.FitGLS(X, y, AR1Covariance(len(y), 0)); err != nil {
+		t.Fatal(err)
+	}
+	for j := range ols.Coef {
+		if math.Abs(ols.Coef[j]-gls.Coef[j]) > 1e-9 {
+			t.Errorf("GLS with identity covariance %v, OLS %v", gls.Coef, ols.Coef)
+		}
+	}
+
+	// diagonal covariance is weighted least squares with w = 1/variance
+	sigma := make([][]float64, len(y))
+	weights := make([]float64, len(y))
+	for i := range sigma {
+		sigma[i] = make([]float64, len(y))
+		sigma[i][i] = 0.5 + rand.Float64()
+		weights[i] = 1 / sigma[i][i]
+	}
+	wls := NewLinearRegression(true)
+	wls.FitWeighted(X, y, weights)
+	gls.FitGLS(X, y, sigma)
+	for j := range wls.Coef {
+		if math.Abs(wls.Coef[j]-gls.Coef[j]) > 1e-9 {
+			t.Errorf("GLS with diagonal covariance %v, WLS %v", gls.Coef, wls.Coef)
+		}
+	}
+	if math.Abs(wls.Intercept-gls.Intercept) > 1e-9 {
+		t.Errorf("intercepts differ: %.6f vs %.6f", gls.Intercept, wls.Intercept)
+	}
+
+	if err := gls.FitGLS(X, y, AR1Covariance(len(y), 0.7)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFitGLSSingular(t *testing.T) {
+	// the second column is twice the first, and the intercept absorbs a
+	// constant column; both are reported like the least squares fits
+	y := []float64{1, 2, 2, 3}
+	sigma := AR1Covariance(len(y), 0.5)
+	for _, X := range [][][]float64{
+		{{1, 2}, {2, 4}, {3, 6}, {4, 8}},
+		{{1, 5}, {2, 5}, {3, 5}, {4, 5}},
+	} {
+		model := NewLinearRegression(true)
+		if err := model.FitGLS(X, y, sigma); err != matrix.ErrSingular {
+			t.Errorf("expected matrix.ErrSingular for %v, got %v", X, err)
+		}
+	}
+}
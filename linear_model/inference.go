@@ -0,0 +1,327 @@
+package linear_model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+)
+
+// covariance estimators for OLS coefficients
+const (
+	NonRobust = "nonrobust" // classical s^2 (X'X)^-1
+	HC0       = "HC0"       // White's heteroscedasticity-consistent estimator
+	HC1       = "HC1"       // HC0 scaled by n / (n - k)
+	HC2       = "HC2"       // squared residuals scaled by 1 / (1 - h_ii)
+	HC3       = "HC3"       // squared residuals scaled by 1 / (1 - h_ii)^2
+)
+
+// statistical inference for a fitted linear regression
+type RegressionSummary struct {
+	Names     []string     // parameter names, "const" first if fitted
+	Params    []float64    // intercept (if fitted) followed by coefficients
+	StdErr    []float64    // standard error of each parameter
+	TValues   []float64    // Params / StdErr
+	PValues   []float64    // two-sided p-values of the t-statistics
+	ConfInt   [][2]float64 // (1 - Alpha) confidence interval of each parameter
+	Alpha     float64      // significance level of ConfInt
+	CovType   string       // covariance estimator used for StdErr
+	CovParams [][]float64  // covariance matrix of Params
+
+	NObs        int     // number of samples
+	DfModel     float64 // parameters excluding the intercept
+	DfResid     float64 // n - parameters
+	RSquared    float64 // centered if an intercept was fit, else uncentered
+	AdjRSquared float64
+	FStatistic  float64 // Wald test that every coefficient (not the intercept) is zero
+	FPValue     float64
+	LogLik      float64 // gaussian log-likelihood
+	AIC         float64
+	BIC         float64
+
+	Residuals       []float64
+	DurbinWatson    float64 // ~2 when residuals are uncorrelated
+	Skew            float64 // of the residuals
+	Kurtosis        float64 // of the residuals, 3 for a normal distribution
+	JarqueBera      float64 // normality test of the residuals
+	JarqueBeraP     float64
+	ConditionNumber float64 // of the design matrix, large values signal collinearity
+}
+
+/*
+ Computes standard errors, t-tests, confidence intervals, goodness of fit
+ and residual diagnostics for a fitted model.
+
+ arguments
+ ---------
+   X, y:    the data the model was fit on
+   covType: NonRobust, HC0, HC1, HC2 or HC3
+   alpha:   significance level for confidence intervals, e.g. 0.05
+
+ returns
+ -------
+   summary, which prints as a table when formatted with %s
+*/
+func (model LinearRegression) Summary(X [][]float64, y []float64, covType string, alpha float64) (*RegressionSummary, error) {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return nil, err
+	}
+	if model.Coef == nil {
+		return nil, errors.New("model has not been fit")
+	}
+
+	Z, params, names := designMatrix(X, model.Coef, model.Intercept, model.FitIntercept)
+	n, k := len(Z), len(params)
+	if n <= k {
+		return nil, errors.New("need more samples than parameters")
+	}
+
+	L, err := matrix.Cholesky(matrix.MatMultTrans(Z, Z))
+	if err != nil {
+		return nil, errors.New("design matrix is singular")
+	}
+	ZtZInv := matrix.CholeskyInverse(L)
+
+	s := new(RegressionSummary)
+	s.Names, s.Params, s.CovType, s.Alpha = names, params, covType, alpha
+	s.NObs = n
+	s.DfResid = float64(n - k)
+	s.DfModel = float64(k)
+	if model.FitIntercept {
+		s.DfModel--
+	}
+
+	s.Residuals = matrix.VecSub(y, model.Predict(X))
+	rss := matrix.VecDot(s.Residuals, s.Residuals)
+
+	s.CovParams, err = olsCovariance(Z, ZtZInv, s.Residuals, rss, covType)
+	if err != nil {
+		return nil, err
+	}
+
+	s.fillTests()
+	s.fillFit(y, rss, model.FitIntercept)
+	s.fillDiagnostics(Z)
+
+	return s, nil
+}
+
+/*
+ the design matrix with a leading column of ones when an intercept was
+ fit, along with the matching parameter vector and names
+*/
+func designMatrix(X [][]float64, coef []float64, intercept float64, fitIntercept bool) ([][]float64, []float64, []string) {
+	var params []float64
+	var names []string
+	if fitIntercept {
+		params = append(params, intercept)
+		names = append(names, "const")
+	}
+	params = append(params, coef...)
+	for j := range coef {
+		names = append(names, fmt.Sprintf("x%d", j))
+	}
+
+	return withOnes(X, fitIntercept), params, names
+}
+
+// X with a leading column of ones if fitIntercept, else X itself
+func withOnes(X [][]float64, fitIntercept bool) [][]float64 {
+	if !fitIntercept {
+		return X
+	}
+
+	Z := make([][]float64, len(X))
+	for i := range X {
+		Z[i] = append([]float64{1}, X[i]...)
+	}
+	return Z
+}
+
+/*
+ covariance of the OLS parameters,
+
+   nonrobust: s^2 (Z'Z)^-1
+   HCx:       (Z'Z)^-1 Z' diag(omega) Z (Z'Z)^-1
+*/
+func olsCovariance(Z, ZtZInv [][]float64, residuals []float64, rss float64, covType string) ([][]float64, error) {
+	n, k := len(Z), len(ZtZInv)
+
+	if covType == NonRobust {
+		cov := matrix.Copy(ZtZInv)
+		matrix.IScalarMult(cov, rss/float64(n-k))
+		return cov, nil
+	}
+
+	omega := make([]float64, n)
+	for i, e := range residuals {
+		// leverage h_ii = z_i'(Z'Z)^-1 z_i
+		h := matrix.VecDot(Z[i], matrix.VecMult(ZtZInv, Z[i]))
+
+		switch covType {
+		case HC0:
+			omega[i] = e * e
+		case HC1:
+			omega[i] = e * e * float64(n) / float64(n-k)
+		case HC2:
+			omega[i] = e * e / (1 - h)
+		case HC3:
+			omega[i] = e * e / ((1 - h) * (1 - h))
+		default:
+			return nil, errors.New("unknown covariance type")
+		}
+	}
+
+	// meat = Z' diag(omega) Z
+	meat := make([][]float64, k)
+	for a := range meat {
+		meat[a] = make([]float64, k)
+	}
+	for i := range Z {
+		for a := 0; a < k; a++ {
+			for b := 0; b < k; b++ {
+				meat[a][b] += omega[i] * Z[i][a] * Z[i][b]
+			}
+		}
+	}
+
+	return matrix.MatMult(matrix.MatMult(ZtZInv, meat), ZtZInv), nil
+}
+
+// t-tests and confidence intervals from CovParams
+func (s *RegressionSummary) fillTests() {
+	k := len(s.Params)
+	s.StdErr = make([]float64, k)
+	s.TValues = make([]float64, k)
+	s.PValues = make([]float64, k)
+	s.ConfInt = make([][2]float64, k)
+
+	q := studentTQuantile(1-s.Alpha/2, s.DfResid)
+	for j := range s.Params {
+		s.StdErr[j] = math.Sqrt(s.CovParams[j][j])
+		s.TValues[j] = s.Params[j] / s.StdErr[j]
+		s.PValues[j] = 2 * studentTCDF(-math.Abs(s.TValues[j]), s.DfResid)
+		s.ConfInt[j] = [2]float64{s.Params[j] - q*s.StdErr[j], s.Params[j] + q*s.StdErr[j]}
+	}
+}
+
+// R^2, F-test and information criteria
+func (s *RegressionSummary) fillFit(y []float64, rss float64, fitIntercept bool) {
+	n := float64(s.NObs)
+
+	mean := 0.0
+	if fitIntercept {
+		for _, v := range y {
+			mean += v / n
+		}
+	}
+	tss := 0.0
+	for _, v := range y {
+		tss += (v - mean) * (v - mean)
+	}
+
+	s.RSquared = 1 - rss/tss
+	dfTotal := s.DfResid + s.DfModel
+	s.AdjRSquared = 1 - (1-s.RSquared)*dfTotal/s.DfResid
+
+	// Wald test of R params = 0 where R selects the non-intercept params
+	offset := 0
+	if fitIntercept {
+		offset = 1
+	}
+	q := len(s.Params) - offset
+	if q > 0 {
+		beta := s.Params[offset:]
+		V := make([][]float64, q)
+		for a := range V {
+			V[a] = s.CovParams[offset+a][offset:]
+		}
+
+		if L, err := matrix.Cholesky(V); err == nil {
+			s.FStatistic = matrix.VecDot(beta, matrix.CholeskySolve(L, beta)) / float64(q)
+			s.FPValue = fSurvival(s.FStatistic, float64(q), s.DfResid)
+		} else {
+			s.FStatistic, s.FPValue = math.NaN(), math.NaN()
+		}
+	}
+
+	k := float64(len(s.Params))
+	s.LogLik = -n / 2 * (math.Log(2*math.Pi) + math.Log(rss/n) + 1)
+	s.AIC = -2*s.LogLik + 2*k
+	s.BIC = -2*s.LogLik + k*math.Log(n)
+}
+
+// residual diagnostics and collinearity of the design matrix Z
+func (s *RegressionSummary) fillDiagnostics(Z [][]float64) {
+	e := s.Residuals
+	n := float64(len(e))
+
+	num, den := 0.0, 0.0
+	for i := range e {
+		if i > 0 {
+			num += (e[i] - e[i-1]) * (e[i] - e[i-1])
+		}
+		den += e[i] * e[i]
+	}
+	s.DurbinWatson = num / den
+
+	mean := 0.0
+	for _, v := range e {
+		mean += v / n
+	}
+	m2, m3, m4 := 0.0, 0.0, 0.0
+	for _, v := range e {
+		d := v - mean
+		m2 += d * d / n
+		m3 += d * d * d / n
+		m4 += d * d * d * d / n
+	}
+	s.Skew = m3 / math.Pow(m2, 1.5)
+	s.Kurtosis = m4 / (m2 * m2)
+	s.JarqueBera = n / 6 * (s.Skew*s.Skew + (s.Kurtosis-3)*(s.Kurtosis-3)/4)
+	s.JarqueBeraP = chi2Survival2(s.JarqueBera)
+
+	values, _ := matrix.SymmetricEigen(matrix.MatMultTrans(Z, Z))
+	s.ConditionNumber = math.Sqrt(values[0] / values[len(values)-1])
+}
+
+// summary table in the style of statsmodels
+func (s RegressionSummary) String() string {
+	var buf bytes.Buffer
+	rule := func(c byte) {
+		buf.Write(bytes.Repeat([]byte{c}, 78))
+		buf.WriteByte('\n')
+	}
+	row := func(k1 string, v1 string, k2 string, v2 string) {
+		fmt.Fprintf(&buf, "%-20s%18s   %-20s%17s\n", k1, v1, k2, v2)
+	}
+
+	fmt.Fprintf(&buf, "%49s\n", "OLS Regression Results")
+	rule('=')
+	row("No. Observations:", fmt.Sprint(s.NObs), "R-squared:", fmt.Sprintf("%.3f", s.RSquared))
+	row("Df Residuals:", fmt.Sprintf("%.0f", s.DfResid), "Adj. R-squared:", fmt.Sprintf("%.3f", s.AdjRSquared))
+	row("Df Model:", fmt.Sprintf("%.0f", s.DfModel), "F-statistic:", fmt.Sprintf("%.4g", s.FStatistic))
+	row("Covariance Type:", s.CovType, "Prob (F-statistic):", fmt.Sprintf("%.3g", s.FPValue))
+	row("Log-Likelihood:", fmt.Sprintf("%.2f", s.LogLik), "AIC:", fmt.Sprintf("%.4g", s.AIC))
+	row("", "", "BIC:", fmt.Sprintf("%.4g", s.BIC))
+	rule('=')
+
+	lo, hi := fmt.Sprintf("[%g", s.Alpha/2), fmt.Sprintf("%g]", 1-s.Alpha/2)
+	fmt.Fprintf(&buf, "%-10s%12s%11s%10s%9s%13s%13s\n", "", "coef", "std err", "t", "P>|t|", lo, hi)
+	rule('-')
+	for j := range s.Params {
+		fmt.Fprintf(&buf, "%-10s%12.4f%11.3f%10.3f%9.3f%13.3f%13.3f\n",
+			s.Names[j], s.Params[j], s.StdErr[j], s.TValues[j], s.PValues[j],
+			s.ConfInt[j][0], s.ConfInt[j][1])
+	}
+	rule('=')
+	row("Durbin-Watson:", fmt.Sprintf("%.3f", s.DurbinWatson), "Jarque-Bera (JB):", fmt.Sprintf("%.3f", s.JarqueBera))
+	row("Skew:", fmt.Sprintf("%.3f", s.Skew), "Prob(JB):", fmt.Sprintf("%.3g", s.JarqueBeraP))
+	row("Kurtosis:", fmt.Sprintf("%.3f", s.Kurtosis), "Cond. No.", fmt.Sprintf("%.3g", s.ConditionNumber))
+	rule('=')
+
+	return buf.String()
+}
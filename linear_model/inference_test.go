@@ -0,0 +1,128 @@
+package linear_model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDistributions(t *testing.T) {
+	cases := []struct {
+		name          string
+		got, expected float64
+	}{
+		{"t cdf", studentTCDF(2, 10), 0.9633059826146},
+		{"t cdf symmetric", studentTCDF(-2, 10), 1 - 0.9633059826146},
+		{"t quantile", studentTQuantile(0.975, 10), 2.2281388519650},
+		{"F survival", fSurvival(3, 2, 10), math.Pow(1.6, -5)},
+		{"normal cdf", normalCDF(1.96), 0.9750021048518},
+		{"normal quantile", normalQuantile(0.975), 1.9599639845401},
+	}
+
+	for _, c := range cases {
+		if math.Abs(c.got-c.expected) > 1e-9 {
+			t.Errorf("%s = %.12f, expected %.12f", c.name, c.got, c.expected)
+		}
+	}
+}
+
+func TestSummarySimpleRegression(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	y := []float64{2.1, 3.9, 6.2, 7.8, 10.1, 12.2, 13.8, 16.1}
+	X := make([][]float64, len(x))
+	for i := range x {
+		X[i] = []float64{x[i]}
+	}
+
+	model := NewLinearRegression(true)
+	model.Fit(X, y)
+
+	s, err := model.Summary(X, y, NonRobust, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// textbook formulas for simple regression
+	n := float64(len(x))
+	xMean := 4.5
+	sxx, rss := 0.0, 0.0
+	for i := range x {
+		sxx += (x[i] - xMean) * (x[i] - xMean)
+		rss += s.Residuals[i] * s.Residuals[i]
+	}
+	seSlope := math.Sqrt(rss / (n - 2) / sxx)
+
+	if math.Abs(s.StdErr[1]-seSlope) > 1e-10 {
+		t.Errorf("slope std err %.6f, expected %.6f", s.StdErr[1], seSlope)
+	}
+	if math.Abs(s.FStatistic-s.TValues[1]*s.TValues[1]) > 1e-6 {
+		t.Errorf("F = %.4f should equal t^2 = %.4f", s.FStatistic, s.TValues[1]*s.TValues[1])
+	}
+	if s.ConfInt[1][0] > s.Params[1] || s.ConfInt[1][1] < s.Params[1] {
+		t.Errorf("confidence interval %v excludes estimate %.4f", s.ConfInt[1], s.Params[1])
+	}
+	if s.PValues[1] > 1e-6 {
+		t.Errorf("slope p-value %.3g should be tiny", s.PValues[1])
+	}
+
+	// the slope row of (Z'Z)^-1 Z' is (x_i - xMean) / sxx and the leverage
+	// is h_ii = 1/n + (x_i - xMean)^2 / sxx, so each HC variance of the
+	// slope is sum (x_i - xMean)^2 omega_i / sxx^2
+	hc := map[string]float64{}
+	for i := range x {
+		d2 := (x[i] - xMean) * (x[i] - xMean)
+		e2 := s.Residuals[i] * s.Residuals[i]
+		h := 1/n + d2/sxx
+		hc[HC0] += d2 * e2
+		hc[HC2] += d2 * e2 / (1 - h)
+		hc[HC3] += d2 * e2 / ((1 - h) * (1 - h))
+	}
+	hc[HC1] = hc[HC0] * n / (n - 2)
+
+	for _, covType := range []string{HC0, HC1, HC2, HC3} {
+		robust, err := model.Summary(X, y, covType, 0.05)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if se := math.Sqrt(hc[covType]) / sxx; math.Abs(robust.StdErr[1]-se) > 1e-10 {
+			t.Errorf("%s slope std err %.6f, expected %.6f", covType, robust.StdErr[1], se)
+		}
+		if robust.CovType != covType {
+			t.Errorf("summary covariance type %q, expected %q", robust.CovType, covType)
+		}
+	}
+
+	// each row of the table starts with a label followed by its values
+	rows := map[string][]string{
+		"No. Observations:": {"8", "R-squared:", fmt.Sprintf("%.3f", s.RSquared)},
+		"Df Residuals:":     {"6", "Adj. R-squared:", fmt.Sprintf("%.3f", s.AdjRSquared)},
+		"Covariance Type:":  {NonRobust, "Prob", "(F-statistic):", fmt.Sprintf("%.3g", s.FPValue)},
+		"Durbin-Watson:":    {fmt.Sprintf("%.3f", s.DurbinWatson), "Jarque-Bera", "(JB):", fmt.Sprintf("%.3f", s.JarqueBera)},
+	}
+	for j, name := range []string{"const", "x0"} {
+		rows[name+" "] = []string{
+			fmt.Sprintf("%.4f", s.Params[j]), fmt.Sprintf("%.3f", s.StdErr[j]),
+			fmt.Sprintf("%.3f", s.TValues[j]), fmt.Sprintf("%.3f", s.PValues[j]),
+			fmt.Sprintf("%.3f", s.ConfInt[j][0]), fmt.Sprintf("%.3f", s.ConfInt[j][1]),
+		}
+	}
+
+	table := s.String()
+	for label, values := range rows {
+		found := false
+		for _, line := range strings.Split(table, "\n") {
+			if strings.HasPrefix(line, label) {
+				found = strings.Join(strings.Fields(line[len(label):]), " ") == strings.Join(values, " ")
+				break
+			}
+		}
+		if !found {
+			t.Errorf("summary table row %q should read %v:\n%s", label, values, table)
+		}
+	}
+
+	if _, err := model.Summary(X, y, "HC9", 0.05); err == nil {
+		t.Error("expected error for unknown covariance type")
+	}
+}
@@ -0,0 +1,492 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+)
+
+const (
+	L1 = "l1"
+	L2 = "l2"
+)
+
+/*
+ binary logistic regression for responses in {0, 1}, minimizing
+
+     sum_i s_i * logloss(y_i, sigmoid(x_i'w + b)) + alpha * penalty(w)
+
+ where s_i is the weight of sample i's class, and penalty is ||w||_1
+ (L1) or 1/2 ||w||^2 (L2). The intercept b is never penalized.
+*/
+type LogisticRegression struct {
+	Penalty      string              // L1 ("l1") or L2 ("l2")
+	Alpha        float64             // regularization strength
+	ClassWeight  map[float64]float64 // weight of each label, nil for uniform
+	FitIntercept bool                // whether to fit an intercept term
+	Tol          float64             // convergence tolerance
+	MaxIter      int                 // maximum number of Newton iterations
+	Coef         []float64           // fitted coefficients, one per feature
+	Intercept    float64             // fitted intercept
+	NIter        int                 // Newton iterations used by the last fit
+}
+
+/*
+ logistic regression constructor
+
+ arguments
+ ---------
+   penalty:      L1 ("l1") or L2 ("l2")
+   alpha:        regularization strength
+   fitIntercept: whether to fit an (unpenalized) intercept
+*/
+func NewLogisticRegression(penalty string, alpha float64, fitIntercept bool) (*LogisticRegression, error) {
+	if penalty != L1 && penalty != L2 {
+		return nil, errors.New("unknown penalty")
+	}
+
+	model := new(LogisticRegression)
+	model.Penalty = penalty
+	model.Alpha = alpha
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-6
+	model.MaxIter = 100
+	return model, nil
+}
+
+// class weights inversely proportional to class frequencies, n / (k * n_c)
+func BalancedClassWeight(y []float64) map[float64]float64 {
+	counts := make(map[float64]float64)
+	for _, label := range y {
+		counts[label]++
+	}
+
+	weights := make(map[float64]float64)
+	for label, count := range counts {
+		weights[label] = float64(len(y)) / (float64(len(counts)) * count)
+	}
+	return weights
+}
+
+/*
+ fit the model with samples (X) and {0, 1} responses (y)
+
+ L2 models are fit with Newton's method and a backtracking line search.
+ L1 models use proximal Newton: each iteration minimizes the penalized
+ quadratic approximation of the log-loss by coordinate descent, then
+ backtracks towards the previous solution.
+*/
+func (model *LogisticRegression) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+	for _, label := range y {
+		if label != 0 && label != 1 {
+			return errors.New("responses should be in {0, 1}")
+		}
+	}
+
+	weights := sampleWeights(y, model.ClassWeight)
+	model.Coef = make([]float64, len(X[0]))
+	model.Intercept = 0
+
+	if model.Penalty == L2 {
+		return model.fitNewton(X, y, weights)
+	}
+	return model.fitProximalNewton(X, y, weights)
+}
+
+// predict P(y = 0) and P(y = 1) for each sample in X
+func (model LogisticRegression) PredictProba(X [][]float64) [][]float64 {
+	proba := make([][]float64, len(X))
+	for i, eta := range predictLinear(X, model.Coef, model.Intercept) {
+		p := sigmoid(eta)
+		proba[i] = []float64{1 - p, p}
+	}
+	return proba
+}
+
+// predict {0, 1} labels for each sample in X
+func (model LogisticRegression) Predict(X [][]float64) []float64 {
+	labels := make([]float64, len(X))
+	for i, eta := range predictLinear(X, model.Coef, model.Intercept) {
+		if eta > 0 {
+			labels[i] = 1
+		}
+	}
+	return labels
+}
+
+func (model *LogisticRegression) fitNewton(X [][]float64, y, weights []float64) error {
+	n, p := len(X), len(X[0])
+	objective := func(coef []float64, b float64) float64 {
+		return logLoss(X, y, weights, coef, b) + 0.5*model.Alpha*matrix.VecDot(coef, coef)
+	}
+	f := objective(model.Coef, model.Intercept)
+
+	for model.NIter = 0; model.NIter < model.MaxIter; model.NIter++ {
+		// gradient and hessian over (coef, intercept)
+		g := make([]float64, p+1)
+		H := make([][]float64, p+1)
+		for j := range H {
+			H[j] = make([]float64, p+1)
+		}
+
+		for i := 0; i < n; i++ {
+			mu := sigmoid(matrix.VecDot(X[i], model.Coef) + model.Intercept)
+			r := weights[i] * (mu - y[i])
+			h := weights[i] * mu * (1 - mu)
+			for j := 0; j <= p; j++ {
+				xj := augmented(X[i], j)
+				g[j] += r * xj
+				for k := 0; k <= j; k++ {
+					H[j][k] += h * xj * augmented(X[i], k)
+				}
+			}
+		}
+
+		for j := 0; j < p; j++ {
+			g[j] += model.Alpha * model.Coef[j]
+			H[j][j] += model.Alpha
+		}
+		if !model.FitIntercept {
+			g[p] = 0
+		}
+		// a tiny ridge keeps H positive definite on separable data
+		for j := 0; j <= p; j++ {
+			H[j][j] += 1e-10
+		}
+
+		if maxAbsValue(g) < model.Tol {
+			return nil
+		}
+
+		// without an intercept, the newton step of the restricted problem
+		// drops the intercept's row and column
+		dim := p
+		if model.FitIntercept {
+			dim = p + 1
+		}
+		L, err := matrix.Cholesky(H[:dim])
+		if err != nil {
+			return err
+		}
+		step := make([]float64, p+1)
+		copy(step, matrix.CholeskySolve(L, matrix.VecScale(-1, g[:dim])))
+
+		// backtracking line search with the Armijo condition
+		slope := matrix.VecDot(g, step)
+		accepted := false
+		var coef []float64
+		var b, fNew float64
+		for t := 1.0; t > 1e-10; t /= 2 {
+			coef = matrix.VecAdd(model.Coef, matrix.VecScale(t, step[:p]))
+			b = model.Intercept + t*step[p]
+			fNew = objective(coef, b)
+			if fNew <= f+1e-4*t*slope {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return errors.New("line search failed to decrease the objective")
+		}
+
+		model.Coef, model.Intercept = coef, b
+		if decrease := f - fNew; decrease >= 0 && decrease <= model.Tol*math.Max(math.Abs(f), 1) {
+			return nil
+		}
+		f = fNew
+	}
+
+	return errors.New("newton's method did not converge")
+}
+
+func (model *LogisticRegression) fitProximalNewton(X [][]float64, y, weights []float64) error {
+	n := len(X)
+	objective := func(coef []float64, b float64) float64 {
+		l1 := 0.0
+		for _, c := range coef {
+			l1 += math.Abs(c)
+		}
+		return logLoss(X, y, weights, coef, b) + model.Alpha*l1
+	}
+	f := objective(model.Coef, model.Intercept)
+
+	h := make([]float64, n)
+	z := make([]float64, n)
+
+	for model.NIter = 0; model.NIter < model.MaxIter; model.NIter++ {
+		// quadratic approximation: 1/2 sum h_i (z_i - x_i'w - b)^2
+		for i := 0; i < n; i++ {
+			eta := matrix.VecDot(X[i], model.Coef) + model.Intercept
+			mu := sigmoid(eta)
+			// floored only to avoid dividing by zero; a larger floor
+			// overstates the curvature of confidently classified samples
+			// and shortens every step on nearly separable data
+			v := math.Max(mu*(1-mu), 1e-12)
+			h[i] = weights[i] * v
+			z[i] = eta + (y[i]-mu)/v
+		}
+
+		coef := append([]float64{}, model.Coef...)
+		b := model.Intercept
+		weightedLassoCD(X, z, h, coef, &b, model.Alpha, model.FitIntercept, model.Tol, 1000)
+
+		// backtrack towards the current solution until the objective drops
+		step := matrix.VecSub(coef, model.Coef)
+		bStep := b - model.Intercept
+		accepted := false
+		var fNew float64
+		for t := 1.0; t > 1e-10; t /= 2 {
+			coef = matrix.VecAdd(model.Coef, matrix.VecScale(t, step))
+			b = model.Intercept + t*bStep
+			fNew = objective(coef, b)
+			if fNew <= f {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return errors.New("line search failed to decrease the objective")
+		}
+
+		model.Coef, model.Intercept = coef, b
+		if f-fNew <= model.Tol*math.Max(math.Abs(f), 1) {
+			return nil
+		}
+		f = fNew
+	}
+
+	return errors.New("proximal newton did not converge")
+}
+
+/*
+ Minimizes 1/2 sum_i h_i (z_i - x_i'w - b)^2 + alpha ||w||_1 in place by
+ cyclic coordinate descent, with an unpenalized intercept b.
+
+ Coordinate descent crawls when features are strongly correlated, so once
+ a sweep leaves the signs of w unchanged it jumps towards the minimum of
+ the quadratic on their orthant (see orthantStep).
+*/
+func weightedLassoCD(X [][]float64, z, h, w []float64, b *float64, alpha float64, fitIntercept bool, tol float64, maxIter int) {
+	n, p := len(X), len(w)
+
+	colNorm := make([]float64, p)
+	hSum := 0.0
+	for i := range X {
+		for j := 0; j < p; j++ {
+			colNorm[j] += h[i] * X[i][j] * X[i][j]
+		}
+		hSum += h[i]
+	}
+
+	R := make([]float64, n)
+	for i := range X {
+		R[i] = z[i] - matrix.VecDot(X[i], w) - *b
+	}
+
+	signs := make([]float64, p)
+	for iter := 0; iter < maxIter; iter++ {
+		maxChange := 0.0
+
+		if fitIntercept {
+			delta := 0.0
+			for i := range R {
+				delta += h[i] * R[i]
+			}
+			delta /= hSum
+			*b += delta
+			for i := range R {
+				R[i] -= delta
+			}
+			maxChange = math.Abs(delta)
+		}
+
+		for j := 0; j < p; j++ {
+			if colNorm[j] == 0 {
+				continue
+			}
+			old := w[j]
+
+			rho := 0.0
+			for i := 0; i < n; i++ {
+				rho += h[i] * X[i][j] * R[i]
+			}
+			rho += old * colNorm[j]
+
+			w[j] = softThreshold(rho, alpha) / colNorm[j]
+			if delta := w[j] - old; delta != 0 {
+				for i := 0; i < n; i++ {
+					R[i] -= X[i][j] * delta
+				}
+				// measured on the linear predictor, so it doesn't depend on
+				// the scale of the feature
+				maxChange = math.Max(maxChange, math.Abs(delta)*math.Sqrt(colNorm[j]/hSum))
+			}
+		}
+
+		if maxChange < tol {
+			return
+		}
+
+		settled := true
+		for j, v := range w {
+			if s := sign(v); s != signs[j] {
+				signs[j] = s
+				settled = false
+			}
+		}
+		if settled {
+			orthantStep(X, h, R, w, b, signs, alpha, fitIntercept)
+		}
+	}
+}
+
+/*
+ Moves (w, b) to the minimizer of the weighted lasso objective over the
+ orthant given by signs, where it is a plain quadratic. When a coefficient
+ would change sign on the way it stops there, drops that coefficient and
+ continues with the rest, as in feature-sign search; the objective
+ decreases along every step. Residuals R are updated in place.
+*/
+func orthantStep(X [][]float64, h, R, w []float64, b *float64, signs []float64, alpha float64, fitIntercept bool) {
+	var active []int
+	for j := range w {
+		if signs[j] != 0 {
+			active = append(active, j)
+		}
+	}
+
+	for len(active) > 0 || fitIntercept {
+		k := len(active)
+		if fitIntercept {
+			k++
+		}
+
+		// normal equations for the step d of the active coefficients (and
+		// the intercept, last): Z'HZ d = Z'HR - alpha signs
+		column := func(i, a int) float64 {
+			if a == len(active) {
+				return 1
+			}
+			return X[i][active[a]]
+		}
+		A := make([][]float64, k)
+		g := make([]float64, k)
+		for a := range A {
+			A[a] = make([]float64, k)
+			for i := range X {
+				xa := h[i] * column(i, a)
+				g[a] += xa * R[i]
+				for c := 0; c <= a; c++ {
+					A[a][c] += xa * column(i, c)
+				}
+			}
+			if a < len(active) {
+				g[a] -= alpha * signs[active[a]]
+			}
+		}
+		L, err := matrix.Cholesky(A)
+		if err != nil {
+			return
+		}
+		d := matrix.CholeskySolve(L, g)
+
+		t, zeroed := 1.0, -1
+		for a, j := range active {
+			if sign(w[j]+d[a]) != signs[j] && -w[j]/d[a] < t {
+				t, zeroed = -w[j]/d[a], a
+			}
+		}
+
+		for a, j := range active {
+			w[j] += t * d[a]
+		}
+		if fitIntercept {
+			*b += t * d[k-1]
+		}
+		for i := range X {
+			for a := range d {
+				R[i] -= t * d[a] * column(i, a)
+			}
+		}
+		if zeroed < 0 {
+			return
+		}
+
+		j := active[zeroed]
+		for i := range X {
+			R[i] += X[i][j] * w[j]
+		}
+		w[j], signs[j] = 0, 0
+		active = append(active[:zeroed], active[zeroed+1:]...)
+	}
+}
+
+// weighted log-loss, sum_i s_i (log(1 + exp(eta_i)) - y_i eta_i)
+func logLoss(X [][]float64, y, weights, coef []float64, b float64) float64 {
+	loss := 0.0
+	for i := range X {
+		eta := matrix.VecDot(X[i], coef) + b
+		loss += weights[i] * (softplus(eta) - y[i]*eta)
+	}
+	return loss
+}
+
+// per-sample weights from per-label weights, 1 for unlisted labels
+func sampleWeights(y []float64, classWeight map[float64]float64) []float64 {
+	weights := make([]float64, len(y))
+	for i, label := range y {
+		weights[i] = 1
+		if w, ok := classWeight[label]; ok {
+			weights[i] = w
+		}
+	}
+	return weights
+}
+
+// x_j for j < len(x), and the intercept's constant 1 for j == len(x)
+func augmented(x []float64, j int) float64 {
+	if j == len(x) {
+		return 1
+	}
+	return x[j]
+}
+
+func sigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// log(1 + exp(x)) without overflow
+func softplus(x float64) float64 {
+	if x > 0 {
+		return x + math.Log1p(math.Exp(-x))
+	}
+	return math.Log1p(math.Exp(x))
+}
+
+// -1, 0 or 1 by the sign of x
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func maxAbsValue(x []float64) float64 {
+	max := 0.0
+	for _, v := range x {
+		max = math.Max(max, math.Abs(v))
+	}
+	return max
+}
@@ -0,0 +1,164 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+func randomClassification(n int, coef []float64, intercept float64) ([][]float64, []float64) {
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = make([]float64, len(coef))
+		for j := range X[i] {
+			X[i][j] = rand.NormFloat64()
+		}
+		if rand.Float64() < sigmoid(matrix.VecDot(X[i], coef)+intercept) {
+			y[i] = 1
+		}
+	}
+	return X, y
+}
+
+func TestLogisticRecoversCoefficients(t *testing.T) {
+	coef := []float64{2, -1, 0.5}
+	X, y := randomClassification(5000, coef, -0.5)
+
+	model, _ := NewLogisticRegression(L2, 1e-6, true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	for j := range coef {
+		if math.Abs(model.Coef[j]-coef[j]) > 0.2 {
+			t.Errorf("coef %v, expected %v", model.Coef, coef)
+			break
+		}
+	}
+	if math.Abs(model.Intercept+0.5) > 0.2 {
+		t.Errorf("intercept %.3f, expected -0.5", model.Intercept)
+	}
+
+	proba := model.PredictProba(X[:10])
+	labels := model.Predict(X[:10])
+	for i := range proba {
+		if math.Abs(proba[i][0]+proba[i][1]-1) > 1e-12 {
+			t.Errorf("probabilities %v don't sum to 1", proba[i])
+		}
+		if (proba[i][1] > 0.5) != (labels[i] == 1) {
+			t.Errorf("label %.0f disagrees with probability %.3f", labels[i], proba[i][1])
+		}
+	}
+}
+
+func TestLogisticNoIntercept(t *testing.T) {
+	// uncentered features couple the coefficients with the intercept, so
+	// the restricted problem needs its own newton step
+	coef := []float64{1.5, -1}
+	X, y := randomClassification(2000, coef, 0)
+	for i := range X {
+		X[i][0] += 2
+		if rand.Float64() < sigmoid(matrix.VecDot(X[i], coef)) {
+			y[i] = 1
+		} else {
+			y[i] = 0
+		}
+	}
+
+	model, _ := NewLogisticRegression(L2, 1e-3, false)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if model.Intercept != 0 {
+		t.Errorf("intercept %.3f, expected 0", model.Intercept)
+	}
+
+	// the gradient of the objective vanishes at the solution, and newton's
+	// method gets there in a handful of steps
+	g := matrix.VecScale(model.Alpha, model.Coef)
+	for i := range X {
+		r := sigmoid(matrix.VecDot(X[i], model.Coef)) - y[i]
+		g = matrix.VecAdd(g, matrix.VecScale(r, X[i]))
+	}
+	if maxAbsValue(g) > 1e-3 {
+		t.Errorf("gradient %v at the solution", g)
+	}
+	if model.NIter > 10 {
+		t.Errorf("%d newton iterations", model.NIter)
+	}
+}
+
+func TestLogisticL1Sparsity(t *testing.T) {
+	X, y := randomClassification(500, []float64{3, 0, 0, 0, -2}, 0)
+
+	model, _ := NewLogisticRegression(L1, 20, true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if model.Coef[0] <= 0 || model.Coef[4] >= 0 {
+		t.Errorf("informative features dropped: %v", model.Coef)
+	}
+
+	model.Alpha = 1e6
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range model.Coef {
+		if c != 0 {
+			t.Errorf("expected all zero coefficients, got %v", model.Coef)
+			break
+		}
+	}
+
+	// with no features the intercept is the log-odds of the positive class
+	mean := 0.0
+	for _, v := range y {
+		mean += v / float64(len(y))
+	}
+	if logit := math.Log(mean / (1 - mean)); math.Abs(model.Intercept-logit) > 1e-4 {
+		t.Errorf("intercept %.4f, expected log-odds %.4f", model.Intercept, logit)
+	}
+}
+
+func TestLogisticClassWeight(t *testing.T) {
+	X, y := randomClassification(1000, []float64{1}, -2)
+
+	plain, _ := NewLogisticRegression(L2, 1, true)
+	plain.Fit(X, y)
+
+	balanced, _ := NewLogisticRegression(L2, 1, true)
+	balanced.ClassWeight = BalancedClassWeight(y)
+	balanced.Fit(X, y)
+
+	if balanced.Intercept <= plain.Intercept {
+		t.Errorf("balancing the rare positive class should raise the intercept (%.3f vs %.3f)",
+			balanced.Intercept, plain.Intercept)
+	}
+
+	if _, err := NewLogisticRegression("l3", 1, true); err == nil {
+		t.Error("expected error for unknown penalty")
+	}
+}
+
+func TestLogisticCancer(t *testing.T) {
+	X, y := datasets.Load("cancer")
+	datasets.RandomShuffle(X, y)
+	XTrain, XTest := X[:400], X[400:]
+	yTrain, yTest := y[:400], y[400:]
+
+	for _, penalty := range []string{L2, L1} {
+		for _, alpha := range []float64{1e-3, 1} {
+			model, _ := NewLogisticRegression(penalty, alpha, true)
+			if err := model.Fit(XTrain, yTrain); err != nil {
+				t.Fatalf("%s, alpha %g: %v", penalty, alpha, err)
+			}
+			if acc := metrics.Accuracy(model.Predict(XTest), yTest); acc < 0.9 {
+				t.Errorf("%s, alpha %g: held out accuracy %.3f", penalty, alpha, acc)
+			}
+		}
+	}
+}
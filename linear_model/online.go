@@ -0,0 +1,135 @@
+package linear_model
+
+import (
+	"errors"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+/*
+ linear regression fit incrementally by recursive least squares. Each
+ sample updates the coefficients and the inverse covariance P with a
+ rank-one (Sherman-Morrison) update in O(p^2), so the model never needs
+ the past samples. With a forgetting factor lambda < 1 a sample seen k
+ updates ago has weight lambda^k, which lets the fit track drift.
+*/
+type RecursiveLeastSquares struct {
+	Forgetting   float64     // forgetting factor lambda in (0, 1]
+	Delta        float64     // P starts as Delta * I; large values mean a weak prior
+	FitIntercept bool        // whether to fit an intercept term
+	Coef         []float64   // current coefficients, one per feature
+	Intercept    float64     // current intercept, 0 unless FitIntercept
+	P            [][]float64 // inverse covariance of the parameters, intercept first
+	NSamples     int         // number of samples seen
+}
+
+/*
+ recursive least squares constructor
+
+ arguments
+ ---------
+   forgetting:   forgetting factor in (0, 1]; 1 weighs all samples equally
+   fitIntercept: whether to fit an intercept
+*/
+func NewRecursiveLeastSquares(forgetting float64, fitIntercept bool) (*RecursiveLeastSquares, error) {
+	if forgetting <= 0 || forgetting > 1 {
+		return nil, errors.New("forgetting factor must be in (0, 1]")
+	}
+
+	model := new(RecursiveLeastSquares)
+	model.Forgetting = forgetting
+	model.Delta = 1e6
+	model.FitIntercept = fitIntercept
+	return model, nil
+}
+
+// update the fit with one sample (x) and its response (y)
+func (model *RecursiveLeastSquares) PartialFit(x []float64, y float64) error {
+	if model.P == nil {
+		model.reset(len(x))
+	}
+	if len(x) != len(model.Coef) {
+		return errors.New("number of features differs from previous samples")
+	}
+
+	z := x
+	theta := model.Coef
+	if model.FitIntercept {
+		z = append([]float64{1}, x...)
+		theta = append([]float64{model.Intercept}, model.Coef...)
+	}
+
+	// gain k = P z / (lambda + z'P z)
+	Pz := matrix.VecMult(model.P, z)
+	k := matrix.VecScale(1/(model.Forgetting+matrix.VecDot(z, Pz)), Pz)
+	theta = matrix.VecAdd(theta, matrix.VecScale(y-matrix.VecDot(z, theta), k))
+
+	// P = (P - k (Pz)') / lambda, symmetrized against round-off
+	for a := range model.P {
+		for b := 0; b <= a; b++ {
+			v := (model.P[a][b] - 0.5*(k[a]*Pz[b]+k[b]*Pz[a])) / model.Forgetting
+			model.P[a][b], model.P[b][a] = v, v
+		}
+	}
+
+	if model.FitIntercept {
+		model.Intercept, model.Coef = theta[0], theta[1:]
+	} else {
+		model.Coef = theta
+	}
+	model.NSamples++
+
+	return nil
+}
+
+// discard any previous state and fit the model with samples (X) and responses (y)
+func (model *RecursiveLeastSquares) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	model.reset(len(X[0]))
+	for i := range X {
+		if err := model.PartialFit(X[i], y[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deep copy of the current state, which keeps fitting independently
+func (model RecursiveLeastSquares) Snapshot() *RecursiveLeastSquares {
+	snapshot := model
+	snapshot.Coef = append([]float64(nil), model.Coef...)
+	if model.P != nil {
+		snapshot.P = matrix.Copy(model.P)
+	}
+	return &snapshot
+}
+
+// predict responses for samples (X)
+func (model RecursiveLeastSquares) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model RecursiveLeastSquares) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+func (model *RecursiveLeastSquares) reset(p int) {
+	k := p
+	if model.FitIntercept {
+		k++
+	}
+
+	model.Coef = make([]float64, p)
+	model.Intercept = 0
+	model.P = matrix.Identity(k)
+	for j := range model.P {
+		model.P[j][j] = model.Delta
+	}
+	model.NSamples = 0
+}
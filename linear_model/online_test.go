@@ -0,0 +1,92 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRecursiveLeastSquaresMatchesOLS(t *testing.T) {
+	X, y, _ := randomProblem(50, 3, 0.5)
+
+	ols := NewLinearRegression(true)
+	ols.Fit(X, y)
+
+	rls, _ := NewRecursiveLeastSquares(1, true)
+	for i := range X {
+		if err := rls.PartialFit(X[i], y[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for j := range ols.Coef {
+		if math.Abs(rls.Coef[j]-ols.Coef[j]) > 1e-4 {
+			t.Errorf("rls %v, ols %v", rls.Coef, ols.Coef)
+		}
+	}
+	if math.Abs(rls.Intercept-ols.Intercept) > 1e-4 {
+		t.Errorf("rls intercept %.6f, ols %.6f", rls.Intercept, ols.Intercept)
+	}
+	if rls.NSamples != len(X) {
+		t.Errorf("saw %d samples, expected %d", rls.NSamples, len(X))
+	}
+}
+
+func TestRecursiveLeastSquaresForgetting(t *testing.T) {
+	forget, _ := NewRecursiveLeastSquares(0.95, false)
+	remember, _ := NewRecursiveLeastSquares(1, false)
+
+	// the slope drifts from 1 to -1 halfway through the stream
+	for i := 0; i < 400; i++ {
+		slope := 1.0
+		if i >= 200 {
+			slope = -1
+		}
+		x := []float64{rand.NormFloat64()}
+		y := slope*x[0] + 0.05*rand.NormFloat64()
+		forget.PartialFit(x, y)
+		remember.PartialFit(x, y)
+	}
+
+	if math.Abs(forget.Coef[0]+1) > 0.05 {
+		t.Errorf("forgetting fit slope %.3f, expected -1", forget.Coef[0])
+	}
+	if math.Abs(remember.Coef[0]) > 0.3 {
+		t.Errorf("non-forgetting fit slope %.3f, expected near 0", remember.Coef[0])
+	}
+}
+
+func TestRecursiveLeastSquaresSnapshot(t *testing.T) {
+	X, y, _ := randomProblem(20, 2, 0.1)
+
+	model, _ := NewRecursiveLeastSquares(1, true)
+	model.Fit(X[:10], y[:10])
+	snapshot := model.Snapshot()
+	coef := append([]float64(nil), snapshot.Coef...)
+
+	for i := 10; i < 20; i++ {
+		model.PartialFit(X[i], y[i])
+	}
+	for j := range coef {
+		if snapshot.Coef[j] != coef[j] {
+			t.Errorf("snapshot changed after further updates")
+		}
+	}
+
+	// the snapshot can be resumed from where it was taken
+	for i := 10; i < 20; i++ {
+		snapshot.PartialFit(X[i], y[i])
+	}
+	for j := range coef {
+		if math.Abs(snapshot.Coef[j]-model.Coef[j]) > 1e-9 {
+			t.Errorf("resumed snapshot %v, model %v", snapshot.Coef, model.Coef)
+		}
+	}
+
+	if err := model.PartialFit([]float64{1}, 1); err == nil {
+		t.Errorf("expected error for wrong number of features")
+	}
+	if _, err := NewRecursiveLeastSquares(0, true); err == nil {
+		t.Errorf("expected error for forgetting factor 0")
+	}
+}
@@ -0,0 +1,235 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/optimization"
+)
+
+const (
+	LP   = "lp"   // exact solution by the simplex method
+	IRLS = "irls" // approximate solution by iteratively reweighted least squares
+)
+
+/*
+ linear quantile regression: for each quantile tau, minimizes the check
+ (pinball) loss
+
+     sum_i rho_tau(y_i - x_i'coef - intercept)
+
+ where rho_tau(r) = tau r for r >= 0 and (tau - 1) r otherwise, so that
+ about a fraction tau of the responses fall below the fit. Quantiles are
+ fit independently (and concurrently), so the fitted lines may cross.
+*/
+type QuantileRegression struct {
+	Quantiles    []float64   // quantiles tau in (0, 1) to fit
+	Method       string      // LP or IRLS
+	FitIntercept bool        // whether to fit an intercept term
+	Tol          float64     // relative change in the check loss at IRLS convergence
+	MaxIter      int         // maximum number of IRLS iterations
+	Coef         [][]float64 // fitted coefficients, one row per quantile
+	Intercept    []float64   // fitted intercept of each quantile
+}
+
+/*
+ quantile regression constructor
+
+ arguments
+ ---------
+   quantiles:    quantiles to fit, each in (0, 1), e.g. {0.5, 0.95}
+   method:       LP ("lp") for the exact linear programming solution, or
+                 IRLS ("irls"), much faster on large problems
+   fitIntercept: whether to fit an intercept
+*/
+func NewQuantileRegression(quantiles []float64, method string, fitIntercept bool) (*QuantileRegression, error) {
+	if method != LP && method != IRLS {
+		return nil, errors.New("unknown method")
+	}
+	if len(quantiles) == 0 {
+		return nil, errors.New("no quantiles given")
+	}
+	for _, tau := range quantiles {
+		if tau <= 0 || tau >= 1 {
+			return nil, errors.New("quantiles must be in (0, 1)")
+		}
+	}
+
+	model := new(QuantileRegression)
+	model.Quantiles = quantiles
+	model.Method = method
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-6
+	model.MaxIter = 1000
+	return model, nil
+}
+
+type quantileResult struct {
+	k         int
+	coef      []float64
+	intercept float64
+	err       error
+}
+
+// fit every quantile with samples (X) and responses (y)
+func (model *QuantileRegression) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	results := make(chan quantileResult, len(model.Quantiles))
+	wg := new(sync.WaitGroup)
+
+	for k, tau := range model.Quantiles {
+		wg.Add(1)
+		go func(k int, tau float64) {
+			defer wg.Done()
+			var coef []float64
+			var intercept float64
+			var err error
+			if model.Method == LP {
+				coef, intercept, err = model.fitLP(X, y, tau)
+			} else {
+				coef, intercept, err = model.fitIRLS(X, y, tau)
+			}
+			results <- quantileResult{k, coef, intercept, err}
+		}(k, tau)
+	}
+
+	wg.Wait()
+	close(results)
+
+	model.Coef = make([][]float64, len(model.Quantiles))
+	model.Intercept = make([]float64, len(model.Quantiles))
+	var err error
+	for result := range results {
+		model.Coef[result.k] = result.coef
+		model.Intercept[result.k] = result.intercept
+		if result.err != nil {
+			err = result.err
+		}
+	}
+
+	return err
+}
+
+/*
+ solves the linear program
+
+     minimize   tau 1'u + (1 - tau) 1'v
+     subject to Z(b+ - b-) + u - v = y
+                b+, b-, u, v >= 0
+
+ where u and v are the positive and negative parts of the residuals
+*/
+func (model *QuantileRegression) fitLP(X [][]float64, y []float64, tau float64) ([]float64, float64, error) {
+	Z := withOnes(X, model.FitIntercept)
+	n, k := len(Z), len(Z[0])
+
+	c := make([]float64, 2*k+2*n)
+	A := make([][]float64, n)
+	for i := range A {
+		A[i] = make([]float64, len(c))
+		for j := 0; j < k; j++ {
+			A[i][j] = Z[i][j]
+			A[i][k+j] = -Z[i][j]
+		}
+		A[i][2*k+i] = 1
+		A[i][2*k+n+i] = -1
+		c[2*k+i] = tau
+		c[2*k+n+i] = 1 - tau
+	}
+
+	result, err := optimization.SimplexStandard(c, A, y)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params := matrix.VecSub(result.X[:k], result.X[k:2*k])
+	if model.FitIntercept {
+		return params[1:], params[0], nil
+	}
+	return params, 0, nil
+}
+
+/*
+ approximates the check loss around the current residuals r by the
+ weighted squares w_i r_i^2 with w_i = rho_tau(r_i) / r_i^2, and refits by
+ weighted least squares until the check loss stops improving
+*/
+func (model *QuantileRegression) fitIRLS(X [][]float64, y []float64, tau float64) ([]float64, float64, error) {
+	ols := NewLinearRegression(model.FitIntercept)
+	if err := ols.Fit(X, y); err != nil {
+		return nil, 0, err
+	}
+
+	weights := make([]float64, len(y))
+	res := residuals(ols, X, y)
+	loss := totalCheckLoss(res, tau)
+
+	for iter := 0; iter < model.MaxIter; iter++ {
+		// residuals are floored relative to their current scale to keep
+		// the weights finite; a floor far below the scale lets the fit
+		// swing between residuals at exactly zero
+		floor := math.Max(1e-3*mad(res), 1e-9*math.Max(mad(y), 1e-12))
+		for i, r := range res {
+			weights[i] = tau / math.Max(math.Abs(r), floor)
+			if r < 0 {
+				weights[i] = (1 - tau) / math.Max(math.Abs(r), floor)
+			}
+		}
+
+		if err := ols.FitWeighted(X, y, weights); err != nil {
+			return nil, 0, err
+		}
+		res = residuals(ols, X, y)
+		prev := loss
+		loss = totalCheckLoss(res, tau)
+		if math.Abs(prev-loss) <= model.Tol*math.Max(prev, 1e-12) {
+			return ols.Coef, ols.Intercept, nil
+		}
+	}
+
+	return ols.Coef, ols.Intercept, errors.New("IRLS did not converge")
+}
+
+// predict each quantile for samples (X), one row per sample ordered as Quantiles
+func (model QuantileRegression) Predict(X [][]float64) [][]float64 {
+	yPred := make([][]float64, len(X))
+	for i := range X {
+		yPred[i] = make([]float64, len(model.Quantiles))
+		for k := range model.Quantiles {
+			yPred[i][k] = matrix.VecDot(model.Coef[k], X[i]) + model.Intercept[k]
+		}
+	}
+	return yPred
+}
+
+// mean check loss of the predictions for X at each quantile
+func (model QuantileRegression) Loss(X [][]float64, y []float64) []float64 {
+	loss := make([]float64, len(model.Quantiles))
+	for i, yPred := range model.Predict(X) {
+		for k, tau := range model.Quantiles {
+			loss[k] += checkLoss(y[i]-yPred[k], tau) / float64(len(y))
+		}
+	}
+	return loss
+}
+
+func totalCheckLoss(residuals []float64, tau float64) float64 {
+	loss := 0.0
+	for _, r := range residuals {
+		loss += checkLoss(r, tau)
+	}
+	return loss
+}
+
+func checkLoss(r, tau float64) float64 {
+	if r < 0 {
+		return (tau - 1) * r
+	}
+	return tau * r
+}
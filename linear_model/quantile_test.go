@@ -0,0 +1,81 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// y = 1 + 2x with noise growing in x, so the quantiles fan out
+func heteroscedastic(n int) ([][]float64, []float64) {
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rand.Float64() * 5}
+		y[i] = 1 + 2*X[i][0] + (0.5+X[i][0])*rand.NormFloat64()
+	}
+	return X, y
+}
+
+func TestQuantileRegression(t *testing.T) {
+	X, y := heteroscedastic(200)
+	quantiles := []float64{0.1, 0.5, 0.95}
+
+	lp, err := NewQuantileRegression(quantiles, LP, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lp.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	irls, _ := NewQuantileRegression(quantiles, IRLS, true)
+	if err := irls.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	lpLoss, irlsLoss := lp.Loss(X, y), irls.Loss(X, y)
+	for k, tau := range quantiles {
+		// the LP solution is optimal, and IRLS should be close to it
+		if lpLoss[k] > irlsLoss[k]+1e-9 || irlsLoss[k] > lpLoss[k]*1.01 {
+			t.Errorf("tau %.2f: LP loss %.6f, IRLS loss %.6f", tau, lpLoss[k], irlsLoss[k])
+		}
+
+		// a fraction tau of the responses lies below the fitted quantile
+		below := 0.0
+		for i, yPred := range lp.Predict(X) {
+			if y[i] < yPred[k] {
+				below += 1 / float64(len(y))
+			}
+		}
+		if math.Abs(below-tau) > 0.02 {
+			t.Errorf("tau %.2f: %.3f of responses below the fit", tau, below)
+		}
+	}
+
+	// the noise grows with x, so the slopes of the quantiles fan out
+	if !(lp.Coef[0][0] < lp.Coef[1][0] && lp.Coef[1][0] < lp.Coef[2][0]) {
+		t.Errorf("slopes %.3f, %.3f, %.3f should increase with tau",
+			lp.Coef[0][0], lp.Coef[1][0], lp.Coef[2][0])
+	}
+}
+
+func TestQuantileRegressionMedian(t *testing.T) {
+	// with a constant feature the median regression through the origin
+	// is the sample median
+	X := [][]float64{{1}, {1}, {1}, {1}, {1}}
+	y := []float64{3, -1, 7, 2, 100}
+
+	model, _ := NewQuantileRegression([]float64{0.5}, LP, false)
+	model.Fit(X, y)
+	if math.Abs(model.Coef[0][0]-3) > 1e-9 {
+		t.Errorf("median %.6f, expected 3", model.Coef[0][0])
+	}
+
+	if _, err := NewQuantileRegression([]float64{1}, LP, true); err == nil {
+		t.Errorf("expected error for tau = 1")
+	}
+	if _, err := NewQuantileRegression([]float64{0.5}, "newton", true); err == nil {
+		t.Errorf("expected error for unknown method")
+	}
+}
@@ -1,12 +1,143 @@
 package linear_model
 
 import (
+	"github.com/emef/go.ml/datasets"
 	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
 )
 
-func LinearRegression(X [][]float64, y []float64) []float64 {
-	Sxx := matrix.MatDirtyInverse(matrix.MatMultTrans(X, X))
-	Sxy := matrix.VecMultTrans(X, y)
-	beta := matrix.VecMult(Sxx, Sxy)
-	return beta
-}
\ No newline at end of file
+// ordinary least squares linear regression
+type LinearRegression struct {
+	Coef         []float64 // fitted coefficients, one per feature
+	Intercept    float64   // fitted intercept, 0 unless FitIntercept
+	FitIntercept bool      // whether to fit an intercept term
+}
+
+/*
+ linear regression constructor
+
+ arguments
+ ---------
+   fitIntercept: whether to fit an intercept; if false the model is
+                 fit through the origin
+*/
+func NewLinearRegression(fitIntercept bool) *LinearRegression {
+	model := new(LinearRegression)
+	model.FitIntercept = fitIntercept
+	return model
+}
+
+/*
+ fit the model with samples (X) and responses (y)
+
+ returns
+ -------
+   matrix.ErrSingular if the columns of X are linearly dependent
+*/
+func (model *LinearRegression) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	Xc, yc, xMean, yMean := center(X, y, model.FitIntercept)
+
+	// beta = (X'X)^-1 X'y
+	coef, err := solveNormal(Xc, yc)
+	if err != nil {
+		return err
+	}
+	model.Coef = coef
+	model.Intercept = yMean - matrix.VecDot(xMean, model.Coef)
+
+	return nil
+}
+
+// predict responses for samples (X)
+func (model LinearRegression) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model LinearRegression) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+/*
+ solves the normal equations X'X beta = X'y by Cholesky factorization
+
+ returns
+ -------
+   (beta, err) where err is matrix.ErrSingular if X'X is not positive
+   definite, or so close to singular that a pivot lost nearly all of the
+   corresponding diagonal entry to the other columns
+*/
+func solveNormal(X [][]float64, y []float64) ([]float64, error) {
+	XtX := matrix.MatMultTrans(X, X)
+	L, err := matrix.Cholesky(XtX)
+	if err != nil {
+		return nil, matrix.ErrSingular
+	}
+	for j := range L {
+		if L[j][j]*L[j][j] <= 1e-12*XtX[j][j] {
+			return nil, matrix.ErrSingular
+		}
+	}
+	return matrix.CholeskySolve(L, matrix.VecMultTrans(X, y)), nil
+}
+
+/*
+ centers the columns of X and y about their means
+
+ returns
+ -------
+   (Xc, yc, xMean, yMean) where Xc and yc are new centered copies; when
+   fitIntercept is false the means are zero and X, y are returned as-is
+*/
+func center(X [][]float64, y []float64, fitIntercept bool) ([][]float64, []float64, []float64, float64) {
+	return weightedCenter(X, y, nil, fitIntercept)
+}
+
+// like center, but about the weighted means; nil weights are uniform
+func weightedCenter(X [][]float64, y, weights []float64, fitIntercept bool) ([][]float64, []float64, []float64, float64) {
+	n, m := len(X), len(X[0])
+	xMean := make([]float64, m)
+	yMean := 0.0
+
+	if !fitIntercept {
+		return X, y, xMean, yMean
+	}
+
+	total := 0.0
+	for i := range X {
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		for j := range X[i] {
+			xMean[j] += w * X[i][j]
+		}
+		yMean += w * y[i]
+		total += w
+	}
+	for j := range xMean {
+		xMean[j] /= total
+	}
+	yMean /= total
+
+	Xc := make([][]float64, n)
+	yc := make([]float64, n)
+	for i := range X {
+		Xc[i] = matrix.VecSub(X[i], xMean)
+		yc[i] = y[i] - yMean
+	}
+
+	return Xc, yc, xMean, yMean
+}
+
+func predictLinear(X [][]float64, coef []float64, intercept float64) []float64 {
+	y := make([]float64, len(X))
+	for i := range X {
+		y[i] = matrix.VecDot(X[i], coef) + intercept
+	}
+	return y
+}
@@ -4,25 +4,28 @@ import (
 	"testing"
 	"fmt"
 	"math/rand"
-	"github.com/emef/go.ml/matrix"
+	"math"
 	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
 	"github.com/emef/go.ml/metrics"
 )
 
 func TestBeta(t *testing.T) {
 	X := [][]float64{{1,0,5}, {2,5,4}, {3,6,5}, {8,1,1}}
 	y := []float64{1, 0.5, 0.75, 0.2}
-	beta := LinearRegression(X, y)
+	model := NewLinearRegression(false)
+	model.Fit(X, y)
 	fmt.Println(y)
-	fmt.Println(matrix.VecMult(X, beta))
+	fmt.Println(model.Predict(X))
 }
 
 func TestSimple(t *testing.T) {
 	X := [][]float64{{1, 0}, {1, 0.5}, {1, 1}, {1, 1.5}}
 	y := []float64{0.3, 0.4, 0.55, 0.6}
-	beta := LinearRegression(X, y)
-	fmt.Println(y, beta)
-	fmt.Println(matrix.VecMult(X, beta))
+	model := NewLinearRegression(false)
+	model.Fit(X, y)
+	fmt.Println(y, model.Coef)
+	fmt.Println(model.Predict(X))
 }
 
 func TestBig(t *testing.T) {
@@ -39,7 +42,7 @@ func TestBig(t *testing.T) {
 		}
 	}
 
-	LinearRegression(X, y)
+	NewLinearRegression(true).Fit(X, y)
 }
 
 
@@ -49,22 +52,82 @@ func TestIris(t *testing.T) {
 	XTrain, XTest := X[:67], X[67:]
 	yTrain, yTest := y[:67], y[67:]
 
-	beta := LinearRegression(XTrain, yTrain)
+	model := NewLinearRegression(true)
+	model.Fit(XTrain, yTrain)
 
 	// validate on held out data
-	yPred := matrix.VecMult(XTest, beta)
+	yPred := model.Predict(XTest)
 	fmt.Println("iris error", metrics.MeanSquaredError(yPred, yTest))
 }
 
 func TestCancer(t *testing.T) {
 	X, y := datasets.Load("cancer")
 	datasets.RandomShuffle(X, y)
+
+	// the last two columns are all zero, which makes X'X singular
+	for i := range X {
+		X[i] = X[i][:30]
+	}
 	XTrain, XTest := X[:67], X[67:]
 	yTrain, yTest := y[:67], y[67:]
 
-	beta := LinearRegression(XTrain, yTrain)
+	model := NewLinearRegression(true)
+	if err := model.Fit(XTrain, yTrain); err != nil {
+		t.Fatal(err)
+	}
 
 	// validate on held out data
-	yPred := matrix.VecMult(XTest, beta)
+	yPred := model.Predict(XTest)
 	fmt.Println("cancer error", metrics.MeanSquaredError(yPred, yTest))
 }
+
+func TestIntercept(t *testing.T) {
+	// y = 3 + 2 x_0 - x_1
+	X := [][]float64{{0, 1}, {1, 0}, {2, 3}, {3, 1}, {4, 4}}
+	y := make([]float64, len(X))
+	for i, x := range X {
+		y[i] = 3 + 2*x[0] - x[1]
+	}
+
+	model := NewLinearRegression(true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(model.Intercept-3) > 1e-9 ||
+		math.Abs(model.Coef[0]-2) > 1e-9 ||
+		math.Abs(model.Coef[1]+1) > 1e-9 {
+		t.Errorf("fit %.3f + %v, expected 3 + [2 -1]", model.Intercept, model.Coef)
+	}
+
+	if score := model.Score(X, y); math.Abs(score-1) > 1e-9 {
+		t.Errorf("R^2 = %.3f, expected 1", score)
+	}
+}
+
+func TestFitErrors(t *testing.T) {
+	model := NewLinearRegression(true)
+	if err := model.Fit(nil, nil); err == nil {
+		t.Error("expected error fitting empty dataset")
+	}
+	if err := model.Fit([][]float64{{1}, {2}}, []float64{1}); err == nil {
+		t.Error("expected error on mismatched samples and responses")
+	}
+}
+
+func TestFitSingular(t *testing.T) {
+	// the second column is twice the first
+	X := [][]float64{{1, 2}, {2, 4}, {3, 6}, {4, 8}}
+	y := []float64{1, 2, 2, 3}
+
+	model := NewLinearRegression(true)
+	if err := model.Fit(X, y); err != matrix.ErrSingular {
+		t.Errorf("expected matrix.ErrSingular, got %v", err)
+	}
+
+	// a constant column is absorbed by the intercept
+	X = [][]float64{{1, 5}, {2, 5}, {3, 5}, {4, 5}}
+	if err := model.Fit(X, y); err != matrix.ErrSingular {
+		t.Errorf("expected matrix.ErrSingular for a constant column, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+// linear least squares with an L2 penalty, alpha * ||coef||^2
+type Ridge struct {
+	Alpha        float64   // regularization strength, must be > 0
+	Coef         []float64 // fitted coefficients, one per feature
+	Intercept    float64   // fitted (unpenalized) intercept
+	FitIntercept bool      // whether to fit an intercept term
+}
+
+/*
+ ridge regression constructor
+
+ arguments
+ ---------
+   alpha:        L2 regularization strength
+   fitIntercept: whether to fit an intercept; the intercept is never
+                 penalized
+*/
+func NewRidge(alpha float64, fitIntercept bool) *Ridge {
+	model := new(Ridge)
+	model.Alpha = alpha
+	model.FitIntercept = fitIntercept
+	return model
+}
+
+// fit the model by solving (X'X + alpha I) coef = X'y with a Cholesky factorization
+func (model *Ridge) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+	if model.Alpha <= 0 {
+		return errors.New("ridge alpha must be positive")
+	}
+
+	Xc, yc, xMean, yMean := center(X, y, model.FitIntercept)
+
+	A := matrix.MatMultTrans(Xc, Xc)
+	for j := range A {
+		A[j][j] += model.Alpha
+	}
+
+	L, err := matrix.Cholesky(A)
+	if err != nil {
+		return err
+	}
+
+	model.Coef = matrix.CholeskySolve(L, matrix.VecMultTrans(Xc, yc))
+	model.Intercept = yMean - matrix.VecDot(xMean, model.Coef)
+
+	return nil
+}
+
+// predict responses for samples (X)
+func (model Ridge) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model Ridge) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+// ridge regression with alpha chosen from a grid by efficient cross-validation
+type RidgeCV struct {
+	Ridge
+	Alphas   []float64 // candidate regularization strengths
+	GCV      bool      // use generalized instead of leave-one-out cross-validation
+	CVErrors []float64 // mean squared cross-validation error of each alpha
+}
+
+/*
+ cross-validated ridge regression constructor
+
+ arguments
+ ---------
+   alphas:       grid of candidate regularization strengths
+   fitIntercept: whether to fit an (unpenalized) intercept
+   gcv:          score alphas with generalized cross-validation rather
+                 than exact leave-one-out
+*/
+func NewRidgeCV(alphas []float64, fitIntercept, gcv bool) *RidgeCV {
+	model := new(RidgeCV)
+	model.Alphas = alphas
+	model.FitIntercept = fitIntercept
+	model.GCV = gcv
+	return model
+}
+
+/*
+ Fits one ridge model per alpha and keeps the one with the lowest
+ cross-validation error.
+
+ With the eigendecomposition X'X = V diag(s) V' and Q = XV, the hat
+ matrix for any alpha is H = Q diag(1/(s + alpha)) Q', plus 11'/n for
+ the intercept when it is fit, so each alpha costs O(np) after a single
+ O(np^2) factorization:
+
+     LOO: mean of ((y_i - yhat_i) / (1 - H_ii))^2
+     GCV: mean of (y_i - yhat_i)^2 / (1 - tr(H)/n)^2
+*/
+func (model *RidgeCV) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+	if len(model.Alphas) == 0 {
+		return errors.New("no alphas to choose from")
+	}
+	for _, alpha := range model.Alphas {
+		if alpha <= 0 {
+			return errors.New("ridge alpha must be positive")
+		}
+	}
+
+	Xc, yc, xMean, yMean := center(X, y, model.FitIntercept)
+	n, p := len(Xc), len(Xc[0])
+
+	s, V := matrix.SymmetricEigen(matrix.MatMultTrans(Xc, Xc))
+	Q := matrix.MatMult(Xc, V)
+	Qy := matrix.VecMultTrans(Q, yc)
+
+	model.CVErrors = make([]float64, len(model.Alphas))
+	bestErr := math.Inf(1)
+
+	for a, alpha := range model.Alphas {
+		// the intercept adds 1/n to every leverage and 1 to the trace
+		shrink := make([]float64, p)
+		trace, h0 := 0.0, 0.0
+		if model.FitIntercept {
+			trace, h0 = 1, 1/float64(n)
+		}
+		for k := range shrink {
+			shrink[k] = 1 / (math.Max(s[k], 0) + alpha)
+			trace += math.Max(s[k], 0) * shrink[k]
+		}
+
+		cvErr := 0.0
+		for i := 0; i < n; i++ {
+			yHat, h := 0.0, h0
+			for k := 0; k < p; k++ {
+				yHat += Q[i][k] * shrink[k] * Qy[k]
+				h += Q[i][k] * Q[i][k] * shrink[k]
+			}
+
+			res := yc[i] - yHat
+			if model.GCV {
+				cvErr += res * res
+			} else {
+				cvErr += math.Pow(res/(1-h), 2)
+			}
+		}
+
+		cvErr /= float64(n)
+		if model.GCV {
+			cvErr /= math.Pow(1-trace/float64(n), 2)
+		}
+		model.CVErrors[a] = cvErr
+
+		if cvErr < bestErr {
+			bestErr = cvErr
+			model.Alpha = alpha
+
+			// coef = V diag(1/(s + alpha)) Q'y
+			for k := range shrink {
+				shrink[k] *= Qy[k]
+			}
+			model.Coef = matrix.VecMult(V, shrink)
+			model.Intercept = yMean - matrix.VecDot(xMean, model.Coef)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+func randomProblem(n, m int, noise float64) ([][]float64, []float64, []float64) {
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	// coefficients are bounded away from zero, so every feature matters
+	coef := make([]float64, m)
+	for j := range coef {
+		coef[j] = 0.5 + rand.Float64()
+		if rand.Intn(2) == 0 {
+			coef[j] = -coef[j]
+		}
+	}
+
+	for i := range X {
+		X[i] = make([]float64, m)
+		for j := range X[i] {
+			X[i][j] = rand.NormFloat64()
+			y[i] += X[i][j] * coef[j]
+		}
+		y[i] += 1 + noise*rand.NormFloat64()
+	}
+
+	return X, y, coef
+}
+
+func TestRidgeShrinks(t *testing.T) {
+	X, y, _ := randomProblem(50, 5, 0.1)
+
+	ols := NewLinearRegression(true)
+	ols.Fit(X, y)
+
+	norm := func(coef []float64) float64 {
+		s := 0.0
+		for _, c := range coef {
+			s += c * c
+		}
+		return s
+	}
+
+	prev := norm(ols.Coef)
+	for _, alpha := range []float64{1e-8, 1, 10, 100} {
+		model := NewRidge(alpha, true)
+		if err := model.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if alpha == 1e-8 {
+			for j := range model.Coef {
+				if math.Abs(model.Coef[j]-ols.Coef[j]) > 1e-6 {
+					t.Errorf("ridge with tiny alpha differs from OLS: %v vs %v", model.Coef, ols.Coef)
+					break
+				}
+			}
+		}
+		if n := norm(model.Coef); n > prev+1e-9 {
+			t.Errorf("coefficient norm grew from %.4f to %.4f at alpha %.1f", prev, n, alpha)
+		} else {
+			prev = n
+		}
+	}
+}
+
+func TestRidgeCVLeaveOneOut(t *testing.T) {
+	X, y, _ := randomProblem(20, 3, 0.5)
+	alphas := []float64{0.1, 1, 10}
+
+	for _, fitIntercept := range []bool{false, true} {
+		cv := NewRidgeCV(alphas, fitIntercept, false)
+		if err := cv.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+
+		// brute force leave-one-out for every alpha
+		for a, alpha := range alphas {
+			sum := 0.0
+			for i := range X {
+				XTrain := append(append([][]float64{}, X[:i]...), X[i+1:]...)
+				yTrain := append(append([]float64{}, y[:i]...), y[i+1:]...)
+				model := NewRidge(alpha, fitIntercept)
+				model.Fit(XTrain, yTrain)
+				sum += math.Pow(model.Predict(X[i:i+1])[0]-y[i], 2)
+			}
+			if loo := sum / float64(len(X)); math.Abs(loo-cv.CVErrors[a]) > 1e-8 {
+				t.Errorf("intercept %v, alpha %.1f: LOO error %.6f, brute force %.6f",
+					fitIntercept, alpha, cv.CVErrors[a], loo)
+			}
+		}
+	}
+}
+
+func TestRidgeCVGeneralized(t *testing.T) {
+	X, y, _ := randomProblem(20, 3, 0.5)
+	alphas := []float64{0.1, 1, 10}
+	n := float64(len(X))
+
+	for _, fitIntercept := range []bool{false, true} {
+		cv := NewRidgeCV(alphas, fitIntercept, true)
+		if err := cv.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+
+		// GCV from the training error and the degrees of freedom, counting
+		// the intercept as one
+		for a, alpha := range alphas {
+			model := NewRidge(alpha, fitIntercept)
+			model.Fit(X, y)
+			Xc, _, _, _ := center(X, y, fitIntercept)
+			s, _ := matrix.SymmetricEigen(matrix.MatMultTrans(Xc, Xc))
+			df := 0.0
+			if fitIntercept {
+				df = 1
+			}
+			for _, v := range s {
+				df += v / (v + alpha)
+			}
+			mse := metrics.MeanSquaredError(model.Predict(X), y)
+			if gcv := mse / math.Pow(1-df/n, 2); math.Abs(gcv-cv.CVErrors[a]) > 1e-8 {
+				t.Errorf("intercept %v, alpha %.1f: GCV error %.6f, expected %.6f",
+					fitIntercept, alpha, cv.CVErrors[a], gcv)
+			}
+		}
+	}
+}
+
+func TestRidgeCVInvalidAlpha(t *testing.T) {
+	X, y, _ := randomProblem(20, 3, 0.5)
+	model := NewRidgeCV([]float64{0.1, 1}, true, false)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	alpha, coef := model.Alpha, model.Coef
+
+	// a bad alpha anywhere is rejected before the earlier ones are tried
+	model.Alphas = []float64{0.01, 0.1, -1}
+	if err := model.Fit(X, y); err == nil {
+		t.Error("expected error for a negative alpha")
+	}
+	if model.Alpha != alpha || &model.Coef[0] != &coef[0] {
+		t.Errorf("failed fit changed the model to alpha %g, coef %v", model.Alpha, model.Coef)
+	}
+}
+
+func TestRidgeCancer(t *testing.T) {
+	X, y := datasets.Load("cancer")
+	datasets.RandomShuffle(X, y)
+	XTrain, XTest := X[:400], X[400:]
+	yTrain, yTest := y[:400], y[400:]
+
+	model := NewRidgeCV([]float64{0.01, 0.1, 1, 10, 100}, true, true)
+	if err := model.Fit(XTrain, yTrain); err != nil {
+		t.Fatal(err)
+	}
+
+	// predicting the mean of the 0/1 responses scores about 0.23
+	yPred := model.Predict(XTest)
+	if mse := metrics.MeanSquaredError(yPred, yTest); mse > 0.15 {
+		t.Errorf("held out MSE %.3f at alpha %g", mse, model.Alpha)
+	}
+}
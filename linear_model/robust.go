@@ -0,0 +1,414 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+// any model that can be fit to and predict real-valued responses
+type Regressor interface {
+	Fit(X [][]float64, y []float64) error
+	Predict(X [][]float64) []float64
+}
+
+/*
+ linear regression with the Huber loss, which is quadratic for residuals
+ within Epsilon robust standard deviations and linear beyond, so a few
+ gross outliers can't dominate the fit. Fit by iteratively reweighted
+ least squares with the scale re-estimated from the median absolute
+ deviation of the residuals at each step.
+*/
+type HuberRegressor struct {
+	Epsilon      float64   // residuals beyond Epsilon * Scale are downweighted
+	FitIntercept bool      // whether to fit an intercept term
+	Tol          float64   // largest coefficient change at convergence
+	MaxIter      int       // maximum number of IRLS iterations
+	Coef         []float64 // fitted coefficients, one per feature
+	Intercept    float64   // fitted intercept, 0 unless FitIntercept
+	Scale        float64   // robust estimate of the residual standard deviation
+	Outliers     []bool    // training samples beyond Epsilon * Scale
+	NIter        int       // IRLS iterations used by the last fit
+}
+
+/*
+ huber regression constructor
+
+ arguments
+ ---------
+   epsilon:      threshold in robust standard deviations, 1.35 gives 95%
+                 efficiency for normal errors
+   fitIntercept: whether to fit an intercept
+*/
+func NewHuberRegressor(epsilon float64, fitIntercept bool) *HuberRegressor {
+	model := new(HuberRegressor)
+	model.Epsilon = epsilon
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-6
+	model.MaxIter = 100
+	return model
+}
+
+// fit the model with samples (X) and responses (y)
+func (model *HuberRegressor) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	ols := NewLinearRegression(model.FitIntercept)
+	if err := ols.Fit(X, y); err != nil {
+		return err
+	}
+	weights := make([]float64, len(y))
+
+	for model.NIter = 1; model.NIter <= model.MaxIter; model.NIter++ {
+		residuals := residuals(ols, X, y)
+		model.Scale = mad(residuals)
+		if model.Scale == 0 {
+			break
+		}
+
+		// psi(r) / r for the Huber loss
+		for i, r := range residuals {
+			u := math.Abs(r) / model.Scale
+			weights[i] = 1
+			if u > model.Epsilon {
+				weights[i] = model.Epsilon / u
+			}
+		}
+
+		prev := append([]float64{ols.Intercept}, ols.Coef...)
+		if err := ols.FitWeighted(X, y, weights); err != nil {
+			return err
+		}
+		next := append([]float64{ols.Intercept}, ols.Coef...)
+		if maxAbsValue(matrix.VecSub(next, prev)) < model.Tol {
+			break
+		}
+	}
+
+	model.Coef, model.Intercept = ols.Coef, ols.Intercept
+	model.Outliers = make([]bool, len(y))
+	for i, r := range residuals(ols, X, y) {
+		model.Outliers[i] = math.Abs(r) > model.Epsilon*model.Scale
+	}
+
+	if model.NIter > model.MaxIter {
+		return errors.New("IRLS did not converge")
+	}
+	return nil
+}
+
+// predict responses for samples (X)
+func (model HuberRegressor) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model HuberRegressor) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+/*
+ RANdom SAmple Consensus: repeatedly fits the base regressor to random
+ minimal subsets and keeps the one agreeing with the most samples, then
+ refits the base regressor to that consensus set of inliers
+*/
+type RANSAC struct {
+	Base              Regressor  // model refit on each subset, and finally on the inliers
+	MinSamples        int        // subset size, 0 for number of features + 1
+	ResidualThreshold float64    // largest inlier residual, 0 for the MAD of y
+	MaxTrials         int        // number of random subsets tried
+	Rand              *rand.Rand // source of the random subsets, nil for the global one
+	InlierMask        []bool     // training samples in the final consensus set
+	NTrials           int        // subsets tried by the last fit
+}
+
+/*
+ RANSAC constructor
+
+ arguments
+ ---------
+   base:       regressor to wrap, e.g. NewLinearRegression(true)
+   minSamples: size of each random subset, 0 for number of features + 1
+   threshold:  absolute residual separating inliers from outliers, 0 for
+               the median absolute deviation of the responses
+*/
+func NewRANSAC(base Regressor, minSamples int, threshold float64) *RANSAC {
+	model := new(RANSAC)
+	model.Base = base
+	model.MinSamples = minSamples
+	model.ResidualThreshold = threshold
+	model.MaxTrials = 100
+	return model
+}
+
+// fit the model with samples (X) and responses (y)
+func (model *RANSAC) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	n := len(X)
+	k := model.MinSamples
+	if k == 0 {
+		k = len(X[0]) + 1
+	}
+	if k > n {
+		return errors.New("fewer samples than MinSamples")
+	}
+	threshold := model.ResidualThreshold
+	if threshold == 0 {
+		threshold = mad(y) * 0.6745
+	}
+
+	var best []bool
+	bestCount, bestLoss := 0, math.Inf(1)
+	subX := make([][]float64, k)
+	subY := make([]float64, k)
+	perm := rand.Perm
+	if model.Rand != nil {
+		perm = model.Rand.Perm
+	}
+
+	for model.NTrials = 0; model.NTrials < model.MaxTrials; model.NTrials++ {
+		for i, idx := range perm(n)[:k] {
+			subX[i], subY[i] = X[idx], y[idx]
+		}
+		if err := model.Base.Fit(subX, subY); err != nil {
+			continue
+		}
+
+		mask := make([]bool, n)
+		count, loss := 0, 0.0
+		for i, r := range residuals(model.Base, X, y) {
+			if math.Abs(r) <= threshold {
+				mask[i] = true
+				count++
+				loss += r * r
+			}
+		}
+
+		// prefer more inliers, then a tighter fit to them
+		if count > bestCount || (count == bestCount && loss < bestLoss) {
+			best, bestCount, bestLoss = mask, count, loss
+		}
+		if bestCount == n {
+			break
+		}
+	}
+
+	if bestCount < k {
+		return errors.New("no consensus set found")
+	}
+
+	inX := make([][]float64, 0, bestCount)
+	inY := make([]float64, 0, bestCount)
+	for i := range best {
+		if best[i] {
+			inX = append(inX, X[i])
+			inY = append(inY, y[i])
+		}
+	}
+	model.InlierMask = best
+
+	return model.Base.Fit(inX, inY)
+}
+
+// predict responses for samples (X) with the base regressor fit to the inliers
+func (model RANSAC) Predict(X [][]float64) []float64 {
+	return model.Base.Predict(X)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model RANSAC) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+/*
+ Theil-Sen estimator: the spatial median of the exact least squares
+ solutions to subsets of (features + 1) samples. It tolerates close to
+ 30% outliers but the number of subsets grows quickly with the number of
+ features, so it is meant for small dimensions; beyond MaxSubpopulation
+ subsets a random sample of them is used.
+*/
+type TheilSen struct {
+	FitIntercept     bool       // whether to fit an intercept term
+	MaxSubpopulation int        // most subsets to solve
+	Rand             *rand.Rand // source of the sampled subsets, nil for the global one
+	Coef             []float64  // fitted coefficients, one per feature
+	Intercept        float64    // fitted intercept, 0 unless FitIntercept
+	NSubpopulation   int        // subsets solved by the last fit
+}
+
+/*
+ Theil-Sen constructor
+
+ arguments
+ ---------
+   fitIntercept: whether to fit an intercept
+*/
+func NewTheilSen(fitIntercept bool) *TheilSen {
+	model := new(TheilSen)
+	model.FitIntercept = fitIntercept
+	model.MaxSubpopulation = 10000
+	return model
+}
+
+// fit the model with samples (X) and responses (y)
+func (model *TheilSen) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	Z := withOnes(X, model.FitIntercept)
+	n, k := len(Z), len(Z[0])
+	if n < k {
+		return errors.New("fewer samples than parameters")
+	}
+
+	var solutions [][]float64
+	A := make([][]float64, k)
+	b := make([]float64, k)
+	solve := func(subset []int) {
+		for i, idx := range subset {
+			A[i], b[i] = Z[idx], y[idx]
+		}
+		if params, err := matrix.Solve(A, b); err == nil {
+			solutions = append(solutions, params)
+		}
+	}
+
+	if binomial(n, k) <= float64(model.MaxSubpopulation) {
+		// every subset, in lexicographic order
+		subset := make([]int, k)
+		for i := range subset {
+			subset[i] = i
+		}
+		for {
+			solve(subset)
+			i := k - 1
+			for i >= 0 && subset[i] == n-k+i {
+				i--
+			}
+			if i < 0 {
+				break
+			}
+			subset[i]++
+			for j := i + 1; j < k; j++ {
+				subset[j] = subset[j-1] + 1
+			}
+		}
+	} else {
+		perm := rand.Perm
+		if model.Rand != nil {
+			perm = model.Rand.Perm
+		}
+		for s := 0; s < model.MaxSubpopulation; s++ {
+			solve(perm(n)[:k])
+		}
+	}
+
+	if len(solutions) == 0 {
+		return errors.New("every subset is singular")
+	}
+	model.NSubpopulation = len(solutions)
+
+	params := spatialMedian(solutions)
+	if model.FitIntercept {
+		model.Intercept, model.Coef = params[0], params[1:]
+	} else {
+		model.Intercept, model.Coef = 0, params
+	}
+
+	return nil
+}
+
+// predict responses for samples (X)
+func (model TheilSen) Predict(X [][]float64) []float64 {
+	return predictLinear(X, model.Coef, model.Intercept)
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model TheilSen) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+/*
+ point minimizing the sum of euclidean distances to the given points,
+ found with Weiszfeld's iteration from the coordinate-wise mean
+*/
+func spatialMedian(points [][]float64) []float64 {
+	m := make([]float64, len(points[0]))
+	for _, p := range points {
+		m = matrix.VecAdd(m, matrix.VecScale(1/float64(len(points)), p))
+	}
+
+	for iter := 0; iter < 300; iter++ {
+		next := make([]float64, len(m))
+		total := 0.0
+		for _, p := range points {
+			d := matrix.VecNorm(matrix.VecSub(p, m))
+			if d < 1e-12 {
+				// skipping a point at the current estimate keeps the
+				// weights finite (a simplification of Vardi & Zhang)
+				continue
+			}
+			next = matrix.VecAdd(next, matrix.VecScale(1/d, p))
+			total += 1 / d
+		}
+		if total == 0 {
+			return m
+		}
+		next = matrix.VecScale(1/total, next)
+
+		step := matrix.VecNorm(matrix.VecSub(next, m))
+		m = next
+		if step < 1e-10*math.Max(matrix.VecNorm(m), 1) {
+			break
+		}
+	}
+
+	return m
+}
+
+// y - model.Predict(X)
+func residuals(model Regressor, X [][]float64, y []float64) []float64 {
+	return matrix.VecSub(y, model.Predict(X))
+}
+
+// median absolute deviation from the median, scaled to estimate the
+// standard deviation of normal data
+func mad(x []float64) float64 {
+	m := median(x)
+	dev := make([]float64, len(x))
+	for i, v := range x {
+		dev[i] = math.Abs(v - m)
+	}
+	return median(dev) / 0.6745
+}
+
+func median(x []float64) float64 {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return 0.5 * (sorted[n/2-1] + sorted[n/2])
+}
+
+// n choose k as a float, to avoid overflow
+func binomial(n, k int) float64 {
+	c := 1.0
+	for i := 0; i < k; i++ {
+		c = c * float64(n-i) / float64(i+1)
+	}
+	return c
+}
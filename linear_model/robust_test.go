@@ -0,0 +1,115 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// y = 2 + 3x plus small noise, with the first nOutliers responses
+// corrupted; seeded so that the outliers are the same on every run
+func corruptedLine(n, nOutliers int) ([][]float64, []float64) {
+	rng := rand.New(rand.NewSource(42))
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rng.Float64() * 10}
+		y[i] = 2 + 3*X[i][0] + 0.1*rng.NormFloat64()
+		if i < nOutliers {
+			y[i] += 50 + 50*rng.Float64()
+		}
+	}
+	return X, y
+}
+
+func checkLine(t *testing.T, name string, coef []float64, intercept, tol float64) {
+	if math.Abs(coef[0]-3) > tol || math.Abs(intercept-2) > 3*tol {
+		t.Errorf("%s: fit %.3f + %.3f x, expected 2 + 3x", name, intercept, coef[0])
+	}
+}
+
+func TestHuberRegressor(t *testing.T) {
+	X, y := corruptedLine(100, 10)
+
+	ols := NewLinearRegression(true)
+	ols.Fit(X, y)
+	if math.Abs(ols.Intercept-2) < 1 {
+		t.Fatalf("outliers should break OLS, got intercept %.3f", ols.Intercept)
+	}
+
+	model := NewHuberRegressor(1.35, true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	checkLine(t, "huber", model.Coef, model.Intercept, 0.05)
+
+	for i := 0; i < 10; i++ {
+		if !model.Outliers[i] {
+			t.Errorf("sample %d should be flagged as an outlier", i)
+		}
+	}
+	if math.Abs(model.Scale-0.1) > 0.05 {
+		t.Errorf("scale %.3f, expected 0.1", model.Scale)
+	}
+}
+
+func TestRANSAC(t *testing.T) {
+	X, y := corruptedLine(100, 30)
+
+	model := NewRANSAC(NewLinearRegression(true), 0, 0.5)
+	model.Rand = rand.New(rand.NewSource(1))
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	base := model.Base.(*LinearRegression)
+	checkLine(t, "ransac", base.Coef, base.Intercept, 0.05)
+
+	for i, inlier := range model.InlierMask {
+		if inlier != (i >= 30) {
+			t.Errorf("sample %d: inlier %v", i, inlier)
+		}
+	}
+
+	// any regressor can be wrapped
+	model = NewRANSAC(NewRidge(0.1, true), 5, 0.5)
+	model.Rand = rand.New(rand.NewSource(1))
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if score := model.Score(X[30:], y[30:]); score < 0.99 {
+		t.Errorf("ridge RANSAC inlier R2 %.3f", score)
+	}
+}
+
+func TestTheilSen(t *testing.T) {
+	X, y := corruptedLine(40, 8)
+
+	model := NewTheilSen(true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	checkLine(t, "theil-sen", model.Coef, model.Intercept, 0.1)
+	if model.NSubpopulation != 40*39/2 {
+		t.Errorf("solved %d subsets, expected all %d", model.NSubpopulation, 40*39/2)
+	}
+
+	// sampled subsets for larger problems
+	X, y = corruptedLine(300, 30)
+	model.MaxSubpopulation = 2000
+	model.Rand = rand.New(rand.NewSource(1))
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	checkLine(t, "sampled theil-sen", model.Coef, model.Intercept, 0.1)
+}
+
+func TestSpatialMedian(t *testing.T) {
+	// for the corners of a square plus a far away point on its diagonal,
+	// the median sits on the diagonal at 1 + 1/sqrt(3)
+	points := [][]float64{{0, 0}, {0, 2}, {2, 0}, {2, 2}, {100, 100}}
+	m := spatialMedian(points)
+	expected := 1 + 1/math.Sqrt(3)
+	if math.Abs(m[0]-expected) > 1e-6 || math.Abs(m[1]-expected) > 1e-6 {
+		t.Errorf("spatial median %v, expected [%.6f %.6f]", m, expected, expected)
+	}
+}
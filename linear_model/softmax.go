@@ -0,0 +1,252 @@
+package linear_model
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/optimization"
+)
+
+/*
+ multinomial logistic (softmax) regression for any number of classes,
+ minimizing
+
+     -sum_i log P(y_i | x_i) + alpha/2 * ||W||^2
+
+ where P(k | x) = exp(W_k'x + b_k) / sum_j exp(W_j'x + b_j). The
+ intercepts b are never penalized.
+*/
+type SoftmaxRegression struct {
+	Alpha        float64     // L2 regularization strength
+	FitIntercept bool        // whether to fit per-class intercepts
+	Tol          float64     // gradient tolerance of the L-BFGS solver on the mean loss
+	Classes      []float64   // sorted distinct labels seen by Fit
+	Labels       []string    // string labels, set by FitLabels
+	Coef         [][]float64 // fitted coefficients, one row per class
+	Intercept    []float64   // fitted intercept of each class
+}
+
+/*
+ softmax regression constructor
+
+ arguments
+ ---------
+   alpha:        L2 regularization strength
+   fitIntercept: whether to fit (unpenalized) per-class intercepts
+*/
+func NewSoftmaxRegression(alpha float64, fitIntercept bool) *SoftmaxRegression {
+	model := new(SoftmaxRegression)
+	model.Alpha = alpha
+	model.FitIntercept = fitIntercept
+	model.Tol = 1e-6
+	return model
+}
+
+// fit the model with samples (X) and class labels (y), which may be any floats
+func (model *SoftmaxRegression) Fit(X [][]float64, y []float64) error {
+	model.Labels = nil
+	return model.fit(X, y)
+}
+
+func (model *SoftmaxRegression) fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	model.Classes = datasets.Classes(y)
+	K, p := len(model.Classes), len(X[0])
+	if K < 2 {
+		return errors.New("need at least two classes")
+	}
+
+	target := make([]int, len(y))
+	for i, label := range y {
+		target[i] = sort.SearchFloat64s(model.Classes, label)
+	}
+
+	// the solver works on whitened features z = W (x - xMean), where W is
+	// the inverse Cholesky factor of an approximate hessian per sample,
+	// cov / 4 + alpha / n; the optimum is the same, but it's found far
+	// faster when features are correlated or have very different scales
+	Xc, _, xMean, _ := center(X, y, model.FitIntercept)
+	n := float64(len(X))
+	hessian := matrix.MatMultTrans(Xc, Xc)
+	matrix.IScalarMult(hessian, 0.25/n)
+	jitter := 0.0
+	for j := range hessian {
+		jitter = math.Max(jitter, 1e-10*hessian[j][j])
+	}
+	for j := range hessian {
+		// without a penalty, a tiny ridge keeps it positive definite
+		hessian[j][j] += math.Max(model.Alpha/n, jitter)
+		if hessian[j][j] == 0 {
+			hessian[j][j] = 1
+		}
+	}
+	L, err := matrix.Cholesky(hessian)
+	if err != nil {
+		return err
+	}
+	W := make([][]float64, p)
+	for j := range W {
+		unit := make([]float64, p)
+		unit[j] = 1
+		W[j] = matrix.ForwardSubstitution(L, unit)
+	}
+	W = matrix.Transpose(W)
+	Z := make([][]float64, len(Xc))
+	for i := range Z {
+		Z[i] = matrix.VecMult(W, Xc[i])
+	}
+
+	// parameters are packed as K rows of p coefficients then K intercepts;
+	// the objective is averaged over samples so that Tol doesn't depend on n
+	theta, err := optimization.LBFGS(func(theta []float64) (float64, []float64) {
+		loss, grad := softmaxLoss(Z, target, theta, K, p, model.FitIntercept)
+		for k := 0; k < K; k++ {
+			// coefficients of x are W'u for coefficients u of z
+			coef := matrix.VecMultTrans(W, theta[k*p:(k+1)*p])
+			loss += 0.5 * model.Alpha * matrix.VecDot(coef, coef)
+			penalty := matrix.VecMult(W, matrix.VecScale(model.Alpha, coef))
+			for j := range penalty {
+				grad[k*p+j] += penalty[j]
+			}
+		}
+		return loss / n, matrix.VecScale(1/n, grad)
+	}, make([]float64, K*(p+1)), 10, model.Tol)
+
+	// map back to the original features
+	model.Coef = make([][]float64, K)
+	model.Intercept = theta[K*p:]
+	for k := range model.Coef {
+		model.Coef[k] = matrix.VecMultTrans(W, theta[k*p:(k+1)*p])
+		model.Intercept[k] -= matrix.VecDot(model.Coef[k], xMean)
+	}
+
+	return err
+}
+
+// fit the model with samples (X) and string class labels
+func (model *SoftmaxRegression) FitLabels(X [][]float64, labels []string) error {
+	names := make(map[string]bool)
+	for _, label := range labels {
+		names[label] = true
+	}
+
+	model.Labels = make([]string, 0, len(names))
+	for name := range names {
+		model.Labels = append(model.Labels, name)
+	}
+	sort.Strings(model.Labels)
+
+	y := make([]float64, len(labels))
+	for i, label := range labels {
+		y[i] = float64(sort.SearchStrings(model.Labels, label))
+	}
+
+	return model.fit(X, y)
+}
+
+// predict the probability of each class (ordered as Classes) for samples (X)
+func (model SoftmaxRegression) PredictProba(X [][]float64) [][]float64 {
+	proba := make([][]float64, len(X))
+	for i := range X {
+		proba[i] = softmax(model.scores(X[i]))
+	}
+	return proba
+}
+
+// predict the most probable class label for samples (X)
+func (model SoftmaxRegression) Predict(X [][]float64) []float64 {
+	y := make([]float64, len(X))
+	for i := range X {
+		y[i] = model.Classes[matrix.VecArgMax(model.scores(X[i]))]
+	}
+	return y
+}
+
+/*
+ predict the most probable string label for samples (X)
+
+ returns
+ -------
+   (labels, err) where err is set if the model wasn't fit by FitLabels
+*/
+func (model SoftmaxRegression) PredictLabels(X [][]float64) ([]string, error) {
+	if model.Labels == nil {
+		return nil, errors.New("model was not fit with string labels")
+	}
+
+	labels := make([]string, len(X))
+	for i, y := range model.Predict(X) {
+		labels[i] = model.Labels[int(y)]
+	}
+	return labels, nil
+}
+
+func (model SoftmaxRegression) scores(x []float64) []float64 {
+	eta := make([]float64, len(model.Coef))
+	for k := range eta {
+		eta[k] = matrix.VecDot(model.Coef[k], x) + model.Intercept[k]
+	}
+	return eta
+}
+
+// negative log-likelihood and its gradient at packed theta
+func softmaxLoss(X [][]float64, target []int, theta []float64, K, p int, fitIntercept bool) (float64, []float64) {
+	loss := 0.0
+	grad := make([]float64, len(theta))
+	eta := make([]float64, K)
+
+	for i := range X {
+		for k := 0; k < K; k++ {
+			eta[k] = matrix.VecDot(theta[k*p:(k+1)*p], X[i]) + theta[K*p+k]
+		}
+
+		lse := logSumExp(eta)
+		loss += lse - eta[target[i]]
+
+		// d/d eta_k = P(k | x_i) - 1{k = y_i}
+		for k := 0; k < K; k++ {
+			r := math.Exp(eta[k] - lse)
+			if k == target[i] {
+				r -= 1
+			}
+			row := grad[k*p : (k+1)*p]
+			for j, x := range X[i] {
+				row[j] += r * x
+			}
+			if fitIntercept {
+				grad[K*p+k] += r
+			}
+		}
+	}
+
+	return loss, grad
+}
+
+// log(sum exp(x)) computed without overflow
+func logSumExp(x []float64) float64 {
+	max := x[matrix.VecArgMax(x)]
+	if math.IsInf(max, 0) {
+		return max
+	}
+
+	sum := 0.0
+	for _, v := range x {
+		sum += math.Exp(v - max)
+	}
+	return max + math.Log(sum)
+}
+
+func softmax(x []float64) []float64 {
+	lse := logSumExp(x)
+	p := make([]float64, len(x))
+	for k, v := range x {
+		p[k] = math.Exp(v - lse)
+	}
+	return p
+}
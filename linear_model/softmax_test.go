@@ -0,0 +1,130 @@
+package linear_model
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+// gaussian blobs around one center per class
+func blobs(n int, centers [][]float64, labels []float64) ([][]float64, []float64) {
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		k := i % len(centers)
+		X[i] = make([]float64, len(centers[k]))
+		for j := range X[i] {
+			X[i][j] = centers[k][j] + 0.5*rand.NormFloat64()
+		}
+		y[i] = labels[k]
+	}
+	return X, y
+}
+
+func TestSoftmaxRegression(t *testing.T) {
+	centers := [][]float64{{0, 3}, {3, 0}, {-3, -3}}
+	X, y := blobs(300, centers, []float64{2.5, -1, 7})
+
+	model := NewSoftmaxRegression(0.1, true)
+	if err := model.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+
+	if acc := metrics.Accuracy(model.Predict(X), y); acc < 0.95 {
+		t.Errorf("accuracy %.3f on separable blobs", acc)
+	}
+
+	for _, p := range model.PredictProba(X[:20]) {
+		sum := 0.0
+		for _, v := range p {
+			sum += v
+		}
+		if len(p) != 3 || math.Abs(sum-1) > 1e-12 {
+			t.Errorf("bad class probabilities %v", p)
+		}
+	}
+
+	// first sample belongs to class 2.5, which sorts second
+	if p := model.PredictProba(X[:1])[0]; matrix.VecArgMax(p) != 1 {
+		t.Errorf("probabilities %v not ordered like classes %v", p, model.Classes)
+	}
+}
+
+func TestSoftmaxLabels(t *testing.T) {
+	centers := [][]float64{{0, 3}, {3, 0}, {-3, -3}}
+	X, y := blobs(150, centers, []float64{0, 1, 2})
+	names := []string{"setosa", "versicolor", "virginica"}
+	labels := make([]string, len(y))
+	for i := range y {
+		labels[i] = names[int(y[i])]
+	}
+
+	model := NewSoftmaxRegression(0.1, true)
+	if err := model.FitLabels(X, labels); err != nil {
+		t.Fatal(err)
+	}
+
+	predicted, err := model.PredictLabels(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	correct := 0
+	for i, label := range predicted {
+		if label == labels[i] {
+			correct++
+		}
+	}
+	if float64(correct)/float64(len(labels)) < 0.95 {
+		t.Errorf("only %d of %d string labels correct", correct, len(labels))
+	}
+
+	// a model fit on float labels has no string labels to predict
+	model.Fit(X, y)
+	if _, err := model.PredictLabels(X); err == nil {
+		t.Errorf("expected an error predicting string labels after Fit")
+	}
+}
+
+func TestSoftmaxCancer(t *testing.T) {
+	// unscaled features, from ~1e-3 to ~1e3, several nearly collinear
+	X, y := datasets.Load("cancer")
+
+	for _, alpha := range []float64{0, 1e-3, 0.1, 1} {
+		model := NewSoftmaxRegression(alpha, true)
+		if err := model.Fit(X, y); err != nil {
+			t.Fatalf("alpha %g: %v", alpha, err)
+		}
+		if acc := metrics.Accuracy(model.Predict(X), y); acc < 0.95 {
+			t.Errorf("alpha %g: training accuracy %.3f", alpha, acc)
+		}
+		if alpha == 0 {
+			continue
+		}
+
+		// with two classes the optimum splits w between the rows as
+		// (-w/2, w/2), which is logistic regression with penalty alpha/2
+		logistic, _ := NewLogisticRegression(L2, alpha/2, true)
+		if err := logistic.Fit(X, y); err != nil {
+			t.Fatalf("alpha %g: %v", alpha, err)
+		}
+		pLogistic := logistic.PredictProba(X)
+		for i, p := range model.PredictProba(X) {
+			if math.Abs(p[1]-pLogistic[i][1]) > 1e-3 {
+				t.Errorf("alpha %g: P(y = 1) %.4f, logistic regression %.4f", alpha, p[1], pLogistic[i][1])
+				break
+			}
+		}
+	}
+}
+
+func TestLogSumExp(t *testing.T) {
+	if v := logSumExp([]float64{1000, 1000}); math.Abs(v-1000-math.Log(2)) > 1e-9 {
+		t.Errorf("logSumExp overflowed: %g", v)
+	}
+	if p := softmax([]float64{-1000, 0}); p[1] != 1 || math.IsNaN(p[0]) {
+		t.Errorf("softmax underflow handled badly: %v", p)
+	}
+}
@@ -0,0 +1,326 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrSingular            = errors.New("matrix is singular")
+	ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
+)
+
+// n x n identity matrix
+func Identity(n int) [][]float64 {
+	I := make([][]float64, n)
+	for i := range I {
+		I[i] = make([]float64, n)
+		I[i][i] = 1
+	}
+	return I
+}
+
+// A', allocated as a new matrix (A need not be square)
+func Transpose(A [][]float64) [][]float64 {
+	n, m := len(A), len(A[0])
+	T := make([][]float64, m)
+	for j := range T {
+		T[j] = make([]float64, n)
+		for i := 0; i < n; i++ {
+			T[j][i] = A[i][j]
+		}
+	}
+	return T
+}
+
+// deep copy of A
+func Copy(A [][]float64) [][]float64 {
+	B := make([][]float64, len(A))
+	for i := range A {
+		B[i] = make([]float64, len(A[i]))
+		copy(B[i], A[i])
+	}
+	return B
+}
+
+/*
+ Cholesky factorization of a symmetric positive definite matrix.
+
+ arguments
+ ---------
+ A: symmetric positive definite matrix (only the lower triangle is read)
+
+ returns
+ -------
+ (L, err) where A = LL' and L is lower triangular
+*/
+func Cholesky(A [][]float64) ([][]float64, error) {
+	n := len(A)
+	L := make([][]float64, n)
+	for i := range L {
+		L[i] = make([]float64, n)
+	}
+
+	for j := 0; j < n; j++ {
+		d := A[j][j]
+		for k := 0; k < j; k++ {
+			d -= L[j][k] * L[j][k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return nil, ErrNotPositiveDefinite
+		}
+		L[j][j] = math.Sqrt(d)
+
+		for i := j + 1; i < n; i++ {
+			s := A[i][j]
+			for k := 0; k < j; k++ {
+				s -= L[i][k] * L[j][k]
+			}
+			L[i][j] = s / L[j][j]
+		}
+	}
+
+	return L, nil
+}
+
+// solves LL'x = b given the Cholesky factor L
+func CholeskySolve(L [][]float64, b []float64) []float64 {
+	n := len(L)
+	x := make([]float64, n)
+
+	// forward substitution: Lz = b
+	for i := 0; i < n; i++ {
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= L[i][k] * x[k]
+		}
+		x[i] = s / L[i][i]
+	}
+
+	// back substitution: L'x = z
+	for i := n - 1; i >= 0; i-- {
+		s := x[i]
+		for k := i + 1; k < n; k++ {
+			s -= L[k][i] * x[k]
+		}
+		x[i] = s / L[i][i]
+	}
+
+	return x
+}
+
+// solves Lx = b for lower triangular L
+func ForwardSubstitution(L [][]float64, b []float64) []float64 {
+	x := make([]float64, len(L))
+	for i := range L {
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= L[i][k] * x[k]
+		}
+		x[i] = s / L[i][i]
+	}
+	return x
+}
+
+// (LL')^-1 given the Cholesky factor L
+func CholeskyInverse(L [][]float64) [][]float64 {
+	n := len(L)
+	inv := make([][]float64, n)
+	for i := range inv {
+		inv[i] = make([]float64, n)
+	}
+
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		col := CholeskySolve(L, e)
+		e[j] = 0
+		for i := 0; i < n; i++ {
+			inv[i][j] = col[i]
+		}
+	}
+	return inv
+}
+
+/*
+ LU factorization with partial pivoting.
+
+ returns
+ -------
+ (LU, perm, err) where
+     LU:   unit lower triangle L and upper triangle U packed together
+     perm: row permutation, row i of PA is row perm[i] of A
+     err:  ErrSingular if a zero pivot was encountered
+*/
+func LU(A [][]float64) ([][]float64, []int, error) {
+	n := len(A)
+	lu := Copy(A)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	for k := 0; k < n; k++ {
+		// find a pivot for column k
+		iMax, iMaxVal := k, math.Abs(lu[k][k])
+		for i := k + 1; i < n; i++ {
+			if val := math.Abs(lu[i][k]); val > iMaxVal {
+				iMax, iMaxVal = i, val
+			}
+		}
+
+		if iMaxVal == 0 {
+			return lu, perm, ErrSingular
+		}
+
+		lu[k], lu[iMax] = lu[iMax], lu[k]
+		perm[k], perm[iMax] = perm[iMax], perm[k]
+
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= lu[i][k] * lu[k][j]
+			}
+		}
+	}
+
+	return lu, perm, nil
+}
+
+// solves Ax = b given the packed LU factors and permutation from LU
+func LUSolve(lu [][]float64, perm []int, b []float64) []float64 {
+	n := len(lu)
+	x := make([]float64, n)
+
+	for i := 0; i < n; i++ {
+		s := b[perm[i]]
+		for k := 0; k < i; k++ {
+			s -= lu[i][k] * x[k]
+		}
+		x[i] = s
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		s := x[i]
+		for k := i + 1; k < n; k++ {
+			s -= lu[i][k] * x[k]
+		}
+		x[i] = s / lu[i][i]
+	}
+
+	return x
+}
+
+// solves the square system Ax = b
+func Solve(A [][]float64, b []float64) ([]float64, error) {
+	lu, perm, err := LU(A)
+	if err != nil {
+		return nil, err
+	}
+	return LUSolve(lu, perm, b), nil
+}
+
+// A^-1, unlike MatDirtyInverse reports singular matrices
+func Inverse(A [][]float64) ([][]float64, error) {
+	n := len(A)
+	lu, perm, err := LU(A)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := make([][]float64, n)
+	for i := range inv {
+		inv[i] = make([]float64, n)
+	}
+
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		col := LUSolve(lu, perm, e)
+		e[j] = 0
+		for i := 0; i < n; i++ {
+			inv[i][j] = col[i]
+		}
+	}
+
+	return inv, nil
+}
+
+/*
+ Eigendecomposition of a symmetric matrix with the cyclic Jacobi method.
+
+ returns
+ -------
+ (values, V) where A = V diag(values) V' and the columns of V are the
+ orthonormal eigenvectors, sorted by decreasing eigenvalue
+*/
+func SymmetricEigen(A [][]float64) ([]float64, [][]float64) {
+	n := len(A)
+	D := Copy(A)
+	V := Identity(n)
+
+	for sweep := 0; sweep < 100; sweep++ {
+		off, norm := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i != j {
+					off += D[i][j] * D[i][j]
+				}
+				norm += D[i][j] * D[i][j]
+			}
+		}
+		if off <= 1e-30*norm || off == 0 {
+			break
+		}
+
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if D[p][q] == 0 {
+					continue
+				}
+
+				// rotation angle that zeroes D[p][q]
+				theta := (D[q][q] - D[p][p]) / (2 * D[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					dkp, dkq := D[k][p], D[k][q]
+					D[k][p] = c*dkp - s*dkq
+					D[k][q] = s*dkp + c*dkq
+				}
+				for k := 0; k < n; k++ {
+					dpk, dqk := D[p][k], D[q][k]
+					D[p][k] = c*dpk - s*dqk
+					D[q][k] = s*dpk + c*dqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := V[k][p], V[k][q]
+					V[k][p] = c*vkp - s*vkq
+					V[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	// selection sort by decreasing eigenvalue, swapping vector columns
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = D[i][i]
+	}
+	for i := 0; i < n; i++ {
+		k := i
+		for j := i + 1; j < n; j++ {
+			if values[j] > values[k] {
+				k = j
+			}
+		}
+		values[i], values[k] = values[k], values[i]
+		for r := 0; r < n; r++ {
+			V[r][i], V[r][k] = V[r][k], V[r][i]
+		}
+	}
+
+	return values, V
+}
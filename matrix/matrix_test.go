@@ -2,6 +2,7 @@ package matrix
 
 import (
 	"fmt"
+	"math"
 	"testing"
 )
 
@@ -36,4 +37,71 @@ func TestCoFactor(t *testing.T) {
 func TestInverse(t *testing.T) {
 	X := [][]float64{{2, -1, 0}, {-1, 2, -1}, {0, -1, 2}}
 	fmt.Println("inverse ", MatDirtyInverse(X))
-}
\ No newline at end of file
+}
+
+func TestCholesky(t *testing.T) {
+	A := [][]float64{{4, 12, -16}, {12, 37, -43}, {-16, -43, 98}}
+	L, err := Cholesky(A)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	LLt := MatMult(L, Transpose(L))
+	for i := range A {
+		for j := range A[i] {
+			if math.Abs(LLt[i][j]-A[i][j]) > 1e-10 {
+				t.Errorf("LL' != A at (%d, %d)", i, j)
+			}
+		}
+	}
+
+	x := CholeskySolve(L, []float64{1, 2, 3})
+	Ax := VecMult(A, x)
+	for i, v := range []float64{1, 2, 3} {
+		if math.Abs(Ax[i]-v) > 1e-8 {
+			t.Errorf("Ax != b: %v", Ax)
+		}
+	}
+
+	if _, err := Cholesky([][]float64{{1, 2}, {2, 1}}); err != ErrNotPositiveDefinite {
+		t.Errorf("expected ErrNotPositiveDefinite, got %v", err)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	A := [][]float64{{0, 2, 1}, {1, 1, 1}, {2, 1, 0}}
+	b := []float64{5, 4, 4}
+	x, err := Solve(A, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []float64{1, 2, 1} {
+		if math.Abs(x[i]-v) > 1e-10 {
+			t.Errorf("Solve returned %v, expected [1 2 1]", x)
+		}
+	}
+
+	if _, err := Inverse([][]float64{{1, 2}, {2, 4}}); err != ErrSingular {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+}
+
+func TestSymmetricEigen(t *testing.T) {
+	A := [][]float64{{4, 1, 2}, {1, 3, 0}, {2, 0, 5}}
+	values, V := SymmetricEigen(A)
+
+	for k := range values {
+		if k > 0 && values[k] > values[k-1] {
+			t.Errorf("eigenvalues not sorted: %v", values)
+		}
+
+		v := []float64{V[0][k], V[1][k], V[2][k]}
+		Av := VecMult(A, v)
+		for i := range v {
+			if math.Abs(Av[i]-values[k]*v[i]) > 1e-9 {
+				t.Errorf("Av != lambda v for eigenvalue %.4f", values[k])
+				break
+			}
+		}
+	}
+}
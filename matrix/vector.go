@@ -1,5 +1,9 @@
 package matrix
 
+import (
+	"math"
+)
+
 func VecScale(scalar float64, X []float64) []float64 {
 	Y := make([]float64, len(X))
 	for i, x := range X {
@@ -14,4 +18,36 @@ func VecAdd(X, Y []float64) []float64 {
 		Z[i] = X[i] + Y[i]
 	}
 	return Z
-}
\ No newline at end of file
+}
+
+func VecSub(X, Y []float64) []float64 {
+	Z := make([]float64, len(X))
+	for i := range X {
+		Z[i] = X[i] - Y[i]
+	}
+	return Z
+}
+
+func VecDot(X, Y []float64) float64 {
+	dot := 0.0
+	for i := range X {
+		dot += X[i] * Y[i]
+	}
+	return dot
+}
+
+// euclidean norm of X
+func VecNorm(X []float64) float64 {
+	return math.Sqrt(VecDot(X, X))
+}
+
+// index of the largest element of X, the first one on ties
+func VecArgMax(X []float64) int {
+	best := 0
+	for i := range X {
+		if X[i] > X[best] {
+			best = i
+		}
+	}
+	return best
+}
@@ -34,3 +34,25 @@ func MeanSquaredError(yPred, yTrue []float64) float64 {
 
 	return err / float64(len(yPred))
 }
+
+
+// coefficient of determination, 1 - SS_res / SS_tot
+func R2(yPred, yTrue []float64) float64 {
+	if len(yPred) != len(yTrue) {
+		return -1
+	}
+
+	mean := 0.0
+	for _, v := range yTrue {
+		mean += v
+	}
+	mean /= float64(len(yTrue))
+
+	ssRes, ssTot := 0.0, 0.0
+	for i := range yPred {
+		ssRes += math.Pow(yTrue[i] - yPred[i], 2)
+		ssTot += math.Pow(yTrue[i] - mean, 2)
+	}
+
+	return 1 - ssRes / ssTot
+}
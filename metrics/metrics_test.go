@@ -9,4 +9,16 @@ func testAccuracy(t *testing.T) {
 	if acc != 0.6 {
 		t.Errorf("Accuracy incorrect (%.2f != %.2f)", acc, 0.6)
 	}
-}
\ No newline at end of file
+}
+
+func TestR2(t *testing.T) {
+	yTrue := []float64{1, 2, 3, 4}
+	if r2 := R2(yTrue, yTrue); r2 != 1 {
+		t.Errorf("R2 of perfect prediction is %.2f", r2)
+	}
+
+	yPred := []float64{2.5, 2.5, 2.5, 2.5}
+	if r2 := R2(yPred, yTrue); r2 != 0 {
+		t.Errorf("R2 of mean prediction is %.2f", r2)
+	}
+}
@@ -0,0 +1,351 @@
+/*
+ Standard test functions for benchmarking and comparing optimizers.
+
+ Each function comes with its analytic gradient, a conventional search
+ domain and starting point, and its known global minima.
+*/
+package functions
+
+import (
+	"fmt"
+	"math"
+)
+
+type Function struct {
+	Name     string
+	Dim      int
+	F        func([]float64) float64
+	Grad     func([]float64) []float64
+	Minima   [][]float64 // locations of the global minima
+	MinValue float64     // F at every point in Minima
+	Lower    []float64   // lower bound of the usual search domain
+	Upper    []float64   // upper bound of the usual search domain
+	Start    []float64   // conventional starting point for local methods
+}
+
+/*
+ Every function in the suite; functions of arbitrary dimension are
+ instantiated with n dimensions (n must be a positive multiple of 4 to
+ include the extended Powell function).
+*/
+func Suite(n int) []Function {
+	suite := []Function{
+		Sphere(n),
+		Rosenbrock(n),
+		Beale(),
+		Booth(),
+		Himmelblau(),
+		Rastrigin(n),
+		Ackley(n),
+		StyblinskiTang(n),
+	}
+	if n%4 == 0 {
+		suite = append(suite, Powell(n))
+	}
+	return suite
+}
+
+// f(x) = sum x_i^2
+func Sphere(n int) Function {
+	return Function{
+		Name: fmt.Sprintf("sphere-%d", n),
+		Dim:  n,
+		F: func(X []float64) float64 {
+			y := 0.0
+			for _, x := range X {
+				y += x * x
+			}
+			return y
+		},
+		Grad: func(X []float64) []float64 {
+			G := make([]float64, len(X))
+			for i, x := range X {
+				G[i] = 2 * x
+			}
+			return G
+		},
+		Minima:   [][]float64{fill(n, 0)},
+		MinValue: 0,
+		Lower:    fill(n, -5),
+		Upper:    fill(n, 5),
+		Start:    fill(n, 3),
+	}
+}
+
+// f(x) = sum 100 (x_{i+1} - x_i^2)^2 + (1 - x_i)^2
+func Rosenbrock(n int) Function {
+	start := make([]float64, n)
+	for i := range start {
+		if i%2 == 0 {
+			start[i] = -1.2
+		} else {
+			start[i] = 1
+		}
+	}
+
+	return Function{
+		Name: fmt.Sprintf("rosenbrock-%d", n),
+		Dim:  n,
+		F: func(X []float64) float64 {
+			y := 0.0
+			for i := 0; i < len(X)-1; i++ {
+				y += 100*math.Pow(X[i+1]-X[i]*X[i], 2) + math.Pow(1-X[i], 2)
+			}
+			return y
+		},
+		Grad: func(X []float64) []float64 {
+			G := make([]float64, len(X))
+			for i := 0; i < len(X)-1; i++ {
+				d := X[i+1] - X[i]*X[i]
+				G[i] += -400*X[i]*d - 2*(1-X[i])
+				G[i+1] += 200 * d
+			}
+			return G
+		},
+		Minima:   [][]float64{fill(n, 1)},
+		MinValue: 0,
+		Lower:    fill(n, -5),
+		Upper:    fill(n, 10),
+		Start:    start,
+	}
+}
+
+// f(x, y) = (1.5 - x + xy)^2 + (2.25 - x + xy^2)^2 + (2.625 - x + xy^3)^2
+func Beale() Function {
+	return Function{
+		Name: "beale",
+		Dim:  2,
+		F: func(X []float64) float64 {
+			x, y := X[0], X[1]
+			return math.Pow(1.5-x+x*y, 2) +
+				math.Pow(2.25-x+x*y*y, 2) +
+				math.Pow(2.625-x+x*y*y*y, 2)
+		},
+		Grad: func(X []float64) []float64 {
+			x, y := X[0], X[1]
+			t1 := 1.5 - x + x*y
+			t2 := 2.25 - x + x*y*y
+			t3 := 2.625 - x + x*y*y*y
+			return []float64{
+				2*t1*(y-1) + 2*t2*(y*y-1) + 2*t3*(y*y*y-1),
+				2*t1*x + 4*t2*x*y + 6*t3*x*y*y,
+			}
+		},
+		Minima:   [][]float64{{3, 0.5}},
+		MinValue: 0,
+		Lower:    fill(2, -4.5),
+		Upper:    fill(2, 4.5),
+		Start:    []float64{1, 1},
+	}
+}
+
+// f(x, y) = (x + 2y - 7)^2 + (2x + y - 5)^2
+func Booth() Function {
+	return Function{
+		Name: "booth",
+		Dim:  2,
+		F: func(X []float64) float64 {
+			x, y := X[0], X[1]
+			return math.Pow(x+2*y-7, 2) + math.Pow(2*x+y-5, 2)
+		},
+		Grad: func(X []float64) []float64 {
+			a := X[0] + 2*X[1] - 7
+			b := 2*X[0] + X[1] - 5
+			return []float64{2*a + 4*b, 4*a + 2*b}
+		},
+		Minima:   [][]float64{{1, 3}},
+		MinValue: 0,
+		Lower:    fill(2, -10),
+		Upper:    fill(2, 10),
+		Start:    []float64{0, 0},
+	}
+}
+
+// f(x, y) = (x^2 + y - 11)^2 + (x + y^2 - 7)^2, four global minima
+func Himmelblau() Function {
+	return Function{
+		Name: "himmelblau",
+		Dim:  2,
+		F: func(X []float64) float64 {
+			x, y := X[0], X[1]
+			return math.Pow(x*x+y-11, 2) + math.Pow(x+y*y-7, 2)
+		},
+		Grad: func(X []float64) []float64 {
+			x, y := X[0], X[1]
+			a := x*x + y - 11
+			b := x + y*y - 7
+			return []float64{4*x*a + 2*b, 2*a + 4*y*b}
+		},
+		Minima: [][]float64{
+			{3, 2},
+			{-2.805118086952745, 3.131312518250573},
+			{-3.779310253377747, -3.283185991286170},
+			{3.584428340330492, -1.848126526964404},
+		},
+		MinValue: 0,
+		Lower:    fill(2, -5),
+		Upper:    fill(2, 5),
+		Start:    []float64{0, 0},
+	}
+}
+
+// f(x) = 10n + sum x_i^2 - 10 cos(2 pi x_i), highly multimodal
+func Rastrigin(n int) Function {
+	return Function{
+		Name: fmt.Sprintf("rastrigin-%d", n),
+		Dim:  n,
+		F: func(X []float64) float64 {
+			y := 10 * float64(len(X))
+			for _, x := range X {
+				y += x*x - 10*math.Cos(2*math.Pi*x)
+			}
+			return y
+		},
+		Grad: func(X []float64) []float64 {
+			G := make([]float64, len(X))
+			for i, x := range X {
+				G[i] = 2*x + 20*math.Pi*math.Sin(2*math.Pi*x)
+			}
+			return G
+		},
+		Minima:   [][]float64{fill(n, 0)},
+		MinValue: 0,
+		Lower:    fill(n, -5.12),
+		Upper:    fill(n, 5.12),
+		Start:    fill(n, 2.5),
+	}
+}
+
+/*
+ f(x) = -20 exp(-0.2 sqrt(sum x_i^2 / n)) - exp(sum cos(2 pi x_i) / n) + 20 + e
+
+ NOTE: the gradient is not defined at the minimum; Grad returns 0 there
+*/
+func Ackley(n int) Function {
+	return Function{
+		Name: fmt.Sprintf("ackley-%d", n),
+		Dim:  n,
+		F: func(X []float64) float64 {
+			N := float64(len(X))
+			s1, s2 := 0.0, 0.0
+			for _, x := range X {
+				s1 += x * x
+				s2 += math.Cos(2 * math.Pi * x)
+			}
+			return -20*math.Exp(-0.2*math.Sqrt(s1/N)) - math.Exp(s2/N) + 20 + math.E
+		},
+		Grad: func(X []float64) []float64 {
+			N := float64(len(X))
+			s1, s2 := 0.0, 0.0
+			for _, x := range X {
+				s1 += x * x
+				s2 += math.Cos(2 * math.Pi * x)
+			}
+			r := math.Sqrt(s1 / N)
+
+			G := make([]float64, len(X))
+			for i, x := range X {
+				if r > 0 {
+					G[i] = 4 * math.Exp(-0.2*r) * x / (N * r)
+				}
+				G[i] += 2 * math.Pi / N * math.Sin(2*math.Pi*x) * math.Exp(s2/N)
+			}
+			return G
+		},
+		Minima:   [][]float64{fill(n, 0)},
+		MinValue: 0,
+		Lower:    fill(n, -32.768),
+		Upper:    fill(n, 32.768),
+		Start:    fill(n, 10),
+	}
+}
+
+/*
+ Extended Powell singular function, n must be a multiple of 4:
+
+     f(x) = sum over blocks (x1 + 10 x2)^2 + 5 (x3 - x4)^2
+                          + (x2 - 2 x3)^4 + 10 (x1 - x4)^4
+
+ the Hessian is singular at the minimum, which slows most methods down
+*/
+func Powell(n int) Function {
+	if n%4 != 0 {
+		panic("extended Powell dimension must be a multiple of 4")
+	}
+
+	start := make([]float64, n)
+	for i := 0; i < n; i += 4 {
+		copy(start[i:], []float64{3, -1, 0, 1})
+	}
+
+	return Function{
+		Name: fmt.Sprintf("powell-%d", n),
+		Dim:  n,
+		F: func(X []float64) float64 {
+			y := 0.0
+			for i := 0; i+3 < len(X); i += 4 {
+				x1, x2, x3, x4 := X[i], X[i+1], X[i+2], X[i+3]
+				y += math.Pow(x1+10*x2, 2) + 5*math.Pow(x3-x4, 2) +
+					math.Pow(x2-2*x3, 4) + 10*math.Pow(x1-x4, 4)
+			}
+			return y
+		},
+		Grad: func(X []float64) []float64 {
+			G := make([]float64, len(X))
+			for i := 0; i+3 < len(X); i += 4 {
+				x1, x2, x3, x4 := X[i], X[i+1], X[i+2], X[i+3]
+				a := x1 + 10*x2
+				b := x3 - x4
+				c := math.Pow(x2-2*x3, 3)
+				d := math.Pow(x1-x4, 3)
+				G[i] = 2*a + 40*d
+				G[i+1] = 20*a + 4*c
+				G[i+2] = 10*b - 8*c
+				G[i+3] = -10*b - 40*d
+			}
+			return G
+		},
+		Minima:   [][]float64{fill(n, 0)},
+		MinValue: 0,
+		Lower:    fill(n, -4),
+		Upper:    fill(n, 5),
+		Start:    start,
+	}
+}
+
+// f(x) = 1/2 sum x_i^4 - 16 x_i^2 + 5 x_i
+func StyblinskiTang(n int) Function {
+	const xMin = -2.903534027771178
+
+	return Function{
+		Name: fmt.Sprintf("styblinski-tang-%d", n),
+		Dim:  n,
+		F: func(X []float64) float64 {
+			y := 0.0
+			for _, x := range X {
+				y += math.Pow(x, 4) - 16*x*x + 5*x
+			}
+			return y / 2
+		},
+		Grad: func(X []float64) []float64 {
+			G := make([]float64, len(X))
+			for i, x := range X {
+				G[i] = 2*x*x*x - 16*x + 2.5
+			}
+			return G
+		},
+		Minima:   [][]float64{fill(n, xMin)},
+		MinValue: 0.5 * float64(n) * (math.Pow(xMin, 4) - 16*xMin*xMin + 5*xMin),
+		Lower:    fill(n, -5),
+		Upper:    fill(n, 5),
+		Start:    fill(n, 0),
+	}
+}
+
+func fill(n int, v float64) []float64 {
+	X := make([]float64, n)
+	for i := range X {
+		X[i] = v
+	}
+	return X
+}
@@ -0,0 +1,54 @@
+package functions
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKnownMinima(t *testing.T) {
+	for _, f := range Suite(4) {
+		for _, X := range f.Minima {
+			if len(X) != f.Dim {
+				t.Errorf("%s: minimum %v has wrong dimension", f.Name, X)
+			}
+			if y := f.F(X); math.Abs(y-f.MinValue) > 1e-8 {
+				t.Errorf("%s: f(%v) = %g, expected %g", f.Name, X, y, f.MinValue)
+			}
+			for _, g := range f.Grad(X) {
+				if math.Abs(g) > 1e-6 {
+					t.Errorf("%s: gradient at minimum is %v", f.Name, f.Grad(X))
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestGradients(t *testing.T) {
+	const h = 1e-6
+
+	for _, f := range Suite(8) {
+		for trial := 0; trial < 10; trial++ {
+			X := make([]float64, f.Dim)
+			for i := range X {
+				X[i] = f.Lower[i] + rand.Float64()*(f.Upper[i]-f.Lower[i])
+			}
+
+			G := f.Grad(X)
+			for i := range X {
+				x_i := X[i]
+				X[i] = x_i + h
+				f1 := f.F(X)
+				X[i] = x_i - h
+				f0 := f.F(X)
+				X[i] = x_i
+
+				approx := (f1 - f0) / (2 * h)
+				if math.Abs(approx-G[i]) > 1e-4*math.Max(1, math.Abs(G[i])) {
+					t.Errorf("%s: dF/dx_%d = %g, finite difference %g", f.Name, i, G[i], approx)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package optimization
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// temperature at the given iteration of simulated annealing
+type Cooling func(iter int) float64
+
+// a local minimizer such as GradientDescent
+type localMethod func(fn, []float64) []float64
+
+// T(k) = T0 * rate^k
+func ExponentialCooling(T0, rate float64) Cooling {
+	return func(iter int) float64 {
+		return T0 * math.Pow(rate, float64(iter))
+	}
+}
+
+// T(k) = T0 * (1 - k/iterations)
+func LinearCooling(T0 float64, iterations int) Cooling {
+	return func(iter int) float64 {
+		return T0 * math.Max(1-float64(iter)/float64(iterations), 0)
+	}
+}
+
+// T(k) = T0 / log(k + e), the classic schedule with convergence guarantees
+func LogarithmicCooling(T0 float64) Cooling {
+	return func(iter int) float64 {
+		return T0 / math.Log(float64(iter)+math.E)
+	}
+}
+
+// rng, or a new source seeded from the global one when rng is nil
+func randomSource(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return rand.New(rand.NewSource(rand.Int63()))
+	}
+	return rng
+}
+
+// checks that lower and upper bound a non-empty box
+func checkBounds(lower, upper []float64) error {
+	if len(lower) == 0 || len(lower) != len(upper) {
+		return errors.New("bounds must have the same, positive dimension")
+	}
+	for i := range lower {
+		if !(lower[i] <= upper[i]) || math.IsInf(lower[i], 0) || math.IsInf(upper[i], 0) {
+			return errors.New("bounds must be finite with lower <= upper")
+		}
+	}
+	return nil
+}
+
+/*
+ Minimizes f with simulated annealing starting from X.
+
+ Each iteration proposes a gaussian perturbation of the current point,
+ always accepting improvements and accepting uphill moves with
+ probability exp(-delta / T).
+
+ arguments
+ ---------
+ f:          function to minimize
+ X:          initial guess
+ step:       standard deviation of proposed moves
+ cooling:    temperature schedule
+ iterations: number of proposals to evaluate
+ rng:        source of the random moves, nil to seed one from the global
+             source
+
+ returns
+ -------
+ best point visited
+*/
+func SimulatedAnnealing(f fn, X []float64, step float64, cooling Cooling, iterations int, rng *rand.Rand) []float64 {
+	rng = randomSource(rng)
+	current := make([]float64, len(X))
+	copy(current, X)
+	y := f(current)
+
+	best := make([]float64, len(X))
+	copy(best, X)
+	yBest := y
+
+	proposal := make([]float64, len(X))
+	for iter := 0; iter < iterations; iter++ {
+		for i := range current {
+			proposal[i] = current[i] + step*rng.NormFloat64()
+		}
+		y1 := f(proposal)
+
+		T := cooling(iter)
+		accept := y1 < y || (T > 0 && rng.Float64() < math.Exp(-(y1-y)/T))
+		if accept {
+			current, proposal = proposal, current
+			y = y1
+			if y < yBest {
+				copy(best, current)
+				yBest = y
+			}
+		}
+	}
+
+	return best
+}
+
+/*
+ Minimizes f over the box [lower, upper] with particle swarm optimization,
+ using the constriction coefficients of Clerc & Kennedy.
+
+ arguments
+ ---------
+ f:            function to minimize
+ lower, upper: bounds of the search domain
+ particles:    swarm size
+ iterations:   number of swarm updates
+ rng:          source of the random draws, nil to seed one from the global
+               source
+
+ returns
+ -------
+ (X, err) where X is the best point found by any particle, and err is set
+ for invalid arguments or if f is NaN or +Inf at every initial position
+*/
+func ParticleSwarm(f fn, lower, upper []float64, particles, iterations int, rng *rand.Rand) ([]float64, error) {
+	if err := checkBounds(lower, upper); err != nil {
+		return nil, err
+	}
+	if particles <= 0 {
+		return nil, errors.New("number of particles must be positive")
+	}
+	if iterations < 0 {
+		return nil, errors.New("number of iterations must not be negative")
+	}
+	rng = randomSource(rng)
+
+	const (
+		inertia   = 0.7298
+		cognitive = 1.49618
+		social    = 1.49618
+	)
+
+	dim := len(lower)
+	position := make([][]float64, particles)
+	velocity := make([][]float64, particles)
+	personal := make([][]float64, particles)
+	yPersonal := make([]float64, particles)
+
+	var global []float64
+	yGlobal := math.Inf(1)
+
+	for p := range position {
+		position[p] = make([]float64, dim)
+		velocity[p] = make([]float64, dim)
+		for i := 0; i < dim; i++ {
+			width := upper[i] - lower[i]
+			position[p][i] = lower[i] + rng.Float64()*width
+			velocity[p][i] = (rng.Float64() - 0.5) * width
+		}
+
+		personal[p] = make([]float64, dim)
+		copy(personal[p], position[p])
+		yPersonal[p] = f(position[p])
+
+		if yPersonal[p] < yGlobal {
+			global, yGlobal = personal[p], yPersonal[p]
+		}
+	}
+	if global == nil {
+		return nil, errors.New("function is NaN or +Inf at every initial position")
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		for p := range position {
+			for i := 0; i < dim; i++ {
+				width := upper[i] - lower[i]
+				velocity[p][i] = inertia*velocity[p][i] +
+					cognitive*rng.Float64()*(personal[p][i]-position[p][i]) +
+					social*rng.Float64()*(global[i]-position[p][i])
+				velocity[p][i] = math.Min(math.Max(velocity[p][i], -width), width)
+				position[p][i] = math.Min(math.Max(position[p][i]+velocity[p][i], lower[i]), upper[i])
+			}
+
+			y := f(position[p])
+			if y < yPersonal[p] {
+				copy(personal[p], position[p])
+				yPersonal[p] = y
+				if y < yGlobal {
+					global, yGlobal = personal[p], y
+				}
+			}
+		}
+	}
+
+	best := make([]float64, dim)
+	copy(best, global)
+	return best, nil
+}
+
+/*
+ Runs a local minimizer from many random starting points in the box
+ [lower, upper] and returns the best local minimum found.
+
+ NOTE: uses CPU-bound go-routines, increase runtime.GOMAXPROCS for
+ multicore processing; f must be safe to call concurrently
+
+ arguments
+ ---------
+ local:        local minimization method, e.g. GradientDescent
+ f:            function to minimize
+ lower, upper: bounds from which starting points are drawn
+ starts:       number of starting points
+ rng:          source of the starting points, nil to seed one from the
+               global source
+
+ returns
+ -------
+ best local minimum found, or an error for invalid bounds or if starts
+ is not positive
+*/
+func MultiStart(local localMethod, f fn, lower, upper []float64, starts int, rng *rand.Rand) ([]float64, error) {
+	if err := checkBounds(lower, upper); err != nil {
+		return nil, err
+	}
+	if starts <= 0 {
+		return nil, errors.New("number of starts must be positive")
+	}
+	rng = randomSource(rng)
+
+	type localResult struct {
+		X []float64
+		y float64
+	}
+
+	// draw starting points up front so the goroutines don't share state
+	points := make([][]float64, starts)
+	for s := range points {
+		points[s] = make([]float64, len(lower))
+		for i := range points[s] {
+			points[s][i] = lower[i] + rng.Float64()*(upper[i]-lower[i])
+		}
+	}
+
+	results := make(chan localResult, starts)
+	wg := new(sync.WaitGroup)
+
+	for _, X0 := range points {
+		wg.Add(1)
+		go func(X0 []float64) {
+			defer wg.Done()
+			X := local(f, X0)
+			results <- localResult{X, f(X)}
+		}(X0)
+	}
+
+	wg.Wait()
+	close(results)
+
+	best := localResult{nil, math.Inf(1)}
+	for result := range results {
+		if best.X == nil || result.y < best.y {
+			best = result
+		}
+	}
+
+	return best.X, nil
+}
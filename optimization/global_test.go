@@ -0,0 +1,90 @@
+package optimization
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// many local minima, global minimum 0 at the origin
+func rastrigin(X []float64) float64 {
+	y := 10 * float64(len(X))
+	for _, x := range X {
+		y += x*x - 10*math.Cos(2*math.Pi*x)
+	}
+	return y
+}
+
+func TestSimulatedAnnealing(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	X := SimulatedAnnealing(rastrigin, []float64{3.2, -2.7}, 0.3, ExponentialCooling(10, 0.999), 20000, rng)
+	if y := rastrigin(X); y > 1.5 {
+		t.Errorf("annealing stuck at %v (f = %.3f)", X, y)
+	}
+}
+
+func TestParticleSwarm(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	lower, upper := []float64{-5.12, -5.12}, []float64{5.12, 5.12}
+	X, err := ParticleSwarm(rastrigin, lower, upper, 40, 200, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y := rastrigin(X); y > 1e-3 {
+		t.Errorf("swarm stuck at %v (f = %.3f)", X, y)
+	}
+	for i := range X {
+		if X[i] < lower[i] || X[i] > upper[i] {
+			t.Errorf("%v outside of bounds", X)
+		}
+	}
+
+	// the same seed gives the same result
+	Y, _ := ParticleSwarm(rastrigin, lower, upper, 40, 200, rand.New(rand.NewSource(1)))
+	if X[0] != Y[0] || X[1] != Y[1] {
+		t.Errorf("seeded swarms found %v and %v", X, Y)
+	}
+}
+
+func TestParticleSwarmErrors(t *testing.T) {
+	lower, upper := []float64{-1, -1}, []float64{1, 1}
+	if _, err := ParticleSwarm(rastrigin, lower, upper, 0, 10, nil); err == nil {
+		t.Errorf("expected an error for no particles")
+	}
+	if _, err := ParticleSwarm(rastrigin, lower, upper, 10, -1, nil); err == nil {
+		t.Errorf("expected an error for negative iterations")
+	}
+	if _, err := ParticleSwarm(rastrigin, lower, []float64{1}, 10, 10, nil); err == nil {
+		t.Errorf("expected an error for mismatched bounds")
+	}
+	if _, err := ParticleSwarm(rastrigin, upper, lower, 10, 10, nil); err == nil {
+		t.Errorf("expected an error for lower > upper")
+	}
+	nan := func(X []float64) float64 { return math.NaN() }
+	if _, err := ParticleSwarm(nan, lower, upper, 10, 10, nil); err == nil {
+		t.Errorf("expected an error for a function that is NaN everywhere")
+	}
+}
+
+func TestMultiStart(t *testing.T) {
+	// double well, the left minimum near -1.04 is the global one
+	f := func(X []float64) float64 {
+		return math.Pow(X[0]*X[0]-1, 2) + 0.3*X[0]
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	X, err := MultiStart(GradientDescent, f, []float64{-2}, []float64{2}, 20, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if X[0] > -0.9 {
+		t.Errorf("multi-start found local minimum %v", X)
+	}
+
+	if _, err := MultiStart(GradientDescent, f, []float64{-2}, []float64{2}, 0, rng); err == nil {
+		t.Errorf("expected an error for no starts")
+	}
+	if _, err := MultiStart(GradientDescent, f, []float64{2}, []float64{-2}, 20, rng); err == nil {
+		t.Errorf("expected an error for lower > upper")
+	}
+}
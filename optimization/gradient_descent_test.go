@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"testing"
+	"github.com/emef/go.ml/optimization/functions"
 )
 
 func fn2d(f func(x, y float64) float64) fn {
@@ -24,3 +25,20 @@ func TestGradientDescent(t *testing.T) {
 
 	fmt.Println(Y, f(Y))
 }
+
+func TestGradientDescentBooth(t *testing.T) {
+	f := functions.Booth()
+	X := GradientDescent(f.F, f.Start)
+	if y := f.F(X); y-f.MinValue > 1e-3 {
+		t.Errorf("gradient descent stopped at %v (f = %.4f)", X, y)
+	}
+}
+
+func BenchmarkGradientDescent(b *testing.B) {
+	suite := []functions.Function{functions.Sphere(4), functions.Booth(), functions.Beale()}
+	for i := 0; i < b.N; i++ {
+		for _, f := range suite {
+			GradientDescent(f.F, f.Start)
+		}
+	}
+}
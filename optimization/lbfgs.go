@@ -0,0 +1,104 @@
+package optimization
+
+import (
+	"math"
+
+	"github.com/emef/go.ml/matrix"
+)
+
+// function returning both its value and gradient at a point
+type gradFn func([]float64) (float64, []float64)
+
+/*
+ Minimizes the smooth function f from the initial guess X with the
+ limited-memory BFGS quasi-Newton method and a backtracking line search.
+
+ arguments
+ ---------
+ f:   function returning (f(X), gradient of f at X)
+ X:   initial guess
+ m:   number of correction pairs kept to approximate the inverse Hessian
+ tol: stop once the largest gradient component falls below tol
+
+ returns
+ -------
+ (X, err) where X is the best point found and err is ErrMaxIterations if
+ tol was not reached, or ErrLineSearch if no step along the search
+ direction decreased f
+*/
+func LBFGS(f gradFn, X []float64, m int, tol float64) ([]float64, error) {
+	x := make([]float64, len(X))
+	copy(x, X)
+	y, g := f(x)
+
+	var S, Y [][]float64
+	var rho []float64
+
+	for iter := 0; iter < maxIter; iter++ {
+		if maxAbs(g) < tol {
+			return x, nil
+		}
+
+		// two-loop recursion: dir = -H g
+		q := matrix.VecScale(-1, g)
+		alpha := make([]float64, len(S))
+		for k := len(S) - 1; k >= 0; k-- {
+			alpha[k] = rho[k] * matrix.VecDot(S[k], q)
+			q = matrix.VecSub(q, matrix.VecScale(alpha[k], Y[k]))
+		}
+		if k := len(S) - 1; k >= 0 {
+			q = matrix.VecScale(matrix.VecDot(S[k], Y[k])/matrix.VecDot(Y[k], Y[k]), q)
+		}
+		for k := range S {
+			beta := rho[k] * matrix.VecDot(Y[k], q)
+			q = matrix.VecAdd(q, matrix.VecScale(alpha[k]-beta, S[k]))
+		}
+		dir := q
+
+		slope := matrix.VecDot(g, dir)
+		if slope >= 0 {
+			// not a descent direction, restart from steepest descent
+			S, Y, rho = nil, nil, nil
+			dir = matrix.VecScale(-1, g)
+			slope = -matrix.VecDot(g, g)
+		}
+
+		// backtracking line search with the Armijo condition
+		t := 1.0
+		if len(S) == 0 {
+			t = math.Min(1, 1/math.Max(matrix.VecNorm(g), 1e-10))
+		}
+		var x1, g1 []float64
+		var y1 float64
+		for ; ; t /= 2 {
+			x1 = matrix.VecAdd(x, matrix.VecScale(t, dir))
+			if t < 1e-20 || maxAbs(matrix.VecSub(x1, x)) == 0 {
+				// the step no longer moves x, so f can't be decreased along dir
+				return x, ErrLineSearch
+			}
+			y1, g1 = f(x1)
+			if y1 <= y+1e-4*t*slope {
+				break
+			}
+		}
+
+		s := matrix.VecSub(x1, x)
+		dg := matrix.VecSub(g1, g)
+		if sy := matrix.VecDot(s, dg); sy > 1e-12 {
+			if len(S) == m {
+				S, Y, rho = S[1:], Y[1:], rho[1:]
+			}
+			S = append(S, s)
+			Y = append(Y, dg)
+			rho = append(rho, 1/sy)
+		}
+
+		converged := y-y1 <= 1e-15*math.Max(math.Abs(y), 1)
+		x, y, g = x1, y1, g1
+		if converged {
+			return x, nil
+		}
+	}
+
+	return x, ErrMaxIterations
+}
@@ -0,0 +1,33 @@
+package optimization
+
+import (
+	"testing"
+	"github.com/emef/go.ml/optimization/functions"
+)
+
+func TestLBFGS(t *testing.T) {
+	for _, f := range []functions.Function{functions.Rosenbrock(4), functions.Beale(), functions.Powell(4)} {
+		fg := func(X []float64) (float64, []float64) {
+			return f.F(X), f.Grad(X)
+		}
+
+		X, err := LBFGS(fg, f.Start, 10, 1e-8)
+		if err != nil {
+			t.Errorf("%s: %v", f.Name, err)
+		}
+		if y := f.F(X); y-f.MinValue > 1e-6 {
+			t.Errorf("%s: stopped at %v (f = %g)", f.Name, X, y)
+		}
+	}
+}
+
+func TestLBFGSLineSearchFailure(t *testing.T) {
+	// a gradient pointing the wrong way makes every step go uphill
+	fg := func(X []float64) (float64, []float64) {
+		return X[0] * X[0], []float64{-2 * X[0]}
+	}
+
+	if _, err := LBFGS(fg, []float64{1}, 10, 1e-8); err != ErrLineSearch {
+		t.Errorf("expected ErrLineSearch, got %v", err)
+	}
+}
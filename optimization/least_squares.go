@@ -0,0 +1,182 @@
+package optimization
+
+import (
+	"errors"
+	"math"
+
+	"github.com/emef/go.ml/matrix"
+)
+
+var ErrNaNResidual = errors.New("residuals are NaN or infinite at the initial guess")
+
+type residualFn func([]float64) []float64
+type jacobianFn func([]float64) [][]float64
+
+type LeastSquaresResult struct {
+	X            []float64   // fitted parameters
+	ResidualNorm float64     // ||r(X)||
+	Covariance   [][]float64 // approximate covariance of X, nil if n <= p
+	Iterations   int         // number of Jacobian evaluations
+}
+
+/*
+ Minimizes the sum of squared residuals ||r(X)||^2 with the
+ Levenberg-Marquardt algorithm.
+
+ Each step solves (J'J + lambda*diag(J'J)) dX = -J'r, decreasing lambda
+ (towards Gauss-Newton) when a step improves the fit and increasing it
+ (towards gradient descent) when it doesn't.
+
+ arguments
+ ---------
+ r:   residual function, maps parameters to a vector of residuals
+ jac: Jacobian of r (rows are residuals, columns are parameters);
+      if nil it is approximated with finite differences
+ X:   initial guess
+ tol: stop once the relative decrease in ||r||^2, the relative step
+      size or the largest gradient component falls below tol
+
+ returns
+ -------
+ (result, err) where err is ErrNaNResidual if ||r(X)||^2 is not finite,
+ ErrLineSearch if no damping gave a step with a finite cost, or
+ ErrMaxIterations if tol was not reached; in the last two cases result
+ holds the best parameters found.
+*/
+func LevenbergMarquardt(r residualFn, jac jacobianFn, X []float64, tol float64) (*LeastSquaresResult, error) {
+	if jac == nil {
+		jac = func(X []float64) [][]float64 {
+			return jacobian(r, X)
+		}
+	}
+
+	x := make([]float64, len(X))
+	copy(x, X)
+	res := r(x)
+	cost := matrix.VecDot(res, res)
+	if math.IsNaN(cost) || math.IsInf(cost, 0) {
+		return nil, ErrNaNResidual
+	}
+	lambda := 1e-3
+
+	result := new(LeastSquaresResult)
+	var err error = ErrMaxIterations
+
+	for iter := 0; iter < maxIter; iter++ {
+		result.Iterations++
+
+		J := jac(x)
+		A := matrix.MatMultTrans(J, J)
+		g := matrix.VecMultTrans(J, res)
+
+		// maxAbs skips NaN, so a NaN gradient must not pass for convergence
+		if maxAbs(g) < tol && !math.IsNaN(matrix.VecDot(g, g)) {
+			err = nil
+			break
+		}
+
+		// increase damping until we find a step that lowers the cost
+		var step, xNew, resNew []float64
+		costNew := math.Inf(1)
+		evaluated := false
+		for lambda < 1e16 {
+			damped := matrix.Copy(A)
+			for i := range damped {
+				damped[i][i] += lambda * math.Max(A[i][i], 1e-12)
+			}
+
+			L, cholErr := matrix.Cholesky(damped)
+			if cholErr == nil {
+				step = matrix.CholeskySolve(L, matrix.VecScale(-1, g))
+				xNew = matrix.VecAdd(x, step)
+				resNew = r(xNew)
+				costNew = matrix.VecDot(resNew, resNew)
+				evaluated = evaluated || !math.IsNaN(costNew)
+				if costNew < cost {
+					break
+				}
+			}
+			lambda *= 10
+		}
+
+		// every damped system was singular or gave a NaN cost
+		if !evaluated {
+			err = ErrLineSearch
+			break
+		}
+
+		// no damping gives an improvement, we're at a minimum
+		if !(costNew < cost) {
+			err = nil
+			break
+		}
+
+		converged := cost-costNew <= tol*cost ||
+			matrix.VecNorm(step) <= tol*(matrix.VecNorm(x)+tol)
+
+		x, res, cost = xNew, resNew, costNew
+		lambda = math.Max(lambda/10, 1e-12)
+
+		if converged {
+			err = nil
+			break
+		}
+	}
+
+	result.X = x
+	result.ResidualNorm = math.Sqrt(cost)
+
+	// cov(X) ~= s^2 (J'J)^-1 where s^2 is the residual variance
+	m, p := len(res), len(x)
+	if m > p {
+		J := jac(x)
+		L, cholErr := matrix.Cholesky(matrix.MatMultTrans(J, J))
+		if cholErr == nil {
+			result.Covariance = matrix.CholeskyInverse(L)
+			matrix.IScalarMult(result.Covariance, cost/float64(m-p))
+		}
+	}
+
+	return result, err
+}
+
+/*
+ Approximates the Jacobian of r at X with forward differences
+
+ returns
+ -------
+ matrix J where J[i][j] = dr_i/dX_j
+*/
+func jacobian(r residualFn, X []float64) [][]float64 {
+	x := make([]float64, len(X))
+	copy(x, X)
+	r0 := r(x)
+
+	J := make([][]float64, len(r0))
+	for i := range J {
+		J[i] = make([]float64, len(x))
+	}
+
+	for j := range x {
+		x_j := x[j]
+		h := eps * math.Max(math.Abs(x_j), 1)
+		x[j] += h
+		r1 := r(x)
+		for i := range J {
+			J[i][j] = (r1[i] - r0[i]) / h
+		}
+		x[j] = x_j
+	}
+
+	return J
+}
+
+func maxAbs(X []float64) float64 {
+	max := 0.0
+	for _, x := range X {
+		if math.Abs(x) > max {
+			max = math.Abs(x)
+		}
+	}
+	return max
+}
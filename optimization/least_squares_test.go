@@ -0,0 +1,77 @@
+package optimization
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLevenbergMarquardt(t *testing.T) {
+	// noisy samples of y = 2.5 exp(-1.3 t)
+	ts := []float64{0, 0.25, 0.5, 0.75, 1, 1.25, 1.5, 1.75, 2}
+	noise := []float64{0.01, -0.02, 0.015, 0, -0.01, 0.005, -0.005, 0.01, -0.01}
+	ys := make([]float64, len(ts))
+	for i, t := range ts {
+		ys[i] = 2.5*math.Exp(-1.3*t) + noise[i]
+	}
+
+	r := func(X []float64) []float64 {
+		res := make([]float64, len(ts))
+		for i, t := range ts {
+			res[i] = X[0]*math.Exp(X[1]*t) - ys[i]
+		}
+		return res
+	}
+
+	jac := func(X []float64) [][]float64 {
+		J := make([][]float64, len(ts))
+		for i, t := range ts {
+			e := math.Exp(X[1] * t)
+			J[i] = []float64{e, X[0] * t * e}
+		}
+		return J
+	}
+
+	for _, j := range []jacobianFn{jac, nil} {
+		result, err := LevenbergMarquardt(r, j, []float64{1, 0}, 1e-12)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if math.Abs(result.X[0]-2.5) > 0.05 || math.Abs(result.X[1]+1.3) > 0.05 {
+			t.Errorf("fit %v, expected [2.5 -1.3]", result.X)
+		}
+		if result.ResidualNorm > 0.05 {
+			t.Errorf("residual norm too large: %.4f", result.ResidualNorm)
+		}
+		if result.Covariance == nil || result.Covariance[0][0] <= 0 || result.Covariance[1][1] <= 0 {
+			t.Errorf("bad covariance %v", result.Covariance)
+		}
+	}
+}
+
+func TestLevenbergMarquardtNaN(t *testing.T) {
+	nan := func(X []float64) []float64 {
+		return []float64{math.NaN(), X[0]}
+	}
+	if _, err := LevenbergMarquardt(nan, nil, []float64{1}, 1e-8); err != ErrNaNResidual {
+		t.Errorf("NaN initial cost: expected ErrNaNResidual, got %v", err)
+	}
+
+	// finite only at the initial guess, so every trial step is NaN
+	spike := func(X []float64) []float64 {
+		if X[0] != 1 {
+			return []float64{math.NaN()}
+		}
+		return []float64{X[0]}
+	}
+	if _, err := LevenbergMarquardt(spike, nil, []float64{1}, 1e-8); err != ErrLineSearch {
+		t.Errorf("NaN trial costs: expected ErrLineSearch, got %v", err)
+	}
+
+	// NaN Jacobian, so the damped system never factors
+	r := func(X []float64) []float64 { return []float64{X[0] - 2} }
+	jac := func(X []float64) [][]float64 { return [][]float64{{math.NaN()}} }
+	if _, err := LevenbergMarquardt(r, jac, []float64{1}, 1e-8); err != ErrLineSearch {
+		t.Errorf("singular damped systems: expected ErrLineSearch, got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package optimization
+
+import (
+	"math"
+
+	"github.com/emef/go.ml/matrix"
+)
+
+const (
+	qpMaxIter = 20 * maxIter
+	qpSigma   = 1e-6 // regularization of the x update
+	qpAlpha   = 1.6  // over-relaxation parameter
+)
+
+type QPResult struct {
+	X          []float64 // primal solution
+	Value      float64   // objective value 1/2 X'PX + q'X
+	Equality   []float64 // multipliers for Ax = b
+	Inequality []float64 // multipliers for Gx <= h, always >= 0
+	Status     Status
+	Iterations int
+}
+
+/*
+ Solves the convex quadratic program
+
+     minimize   1/2 x'Px + q'x
+     subject to Gx <= h
+                Ax  = b
+
+ with the alternating direction method of multipliers (ADMM), as in OSQP.
+ The constraints are stacked as l <= Cx <= u and each iteration solves a
+ single SPD system whose Cholesky factor is reused until the step size
+ rho is rescaled.
+
+ arguments
+ ---------
+ P:    symmetric positive semidefinite n x n matrix
+ q:    linear objective term
+ G, h: inequality constraints (may be nil)
+ A, b: equality constraints (may be nil)
+ tol:  absolute and relative tolerance on the primal and dual residuals
+
+ returns
+ -------
+ (result, err) where
+     result.Equality and result.Inequality satisfy
+     Px + q + A'Equality + G'Inequality = 0 at the solution.
+     err is ErrInfeasible, ErrUnbounded or ErrMaxIterations when no
+     solution was found, or matrix.ErrNotPositiveDefinite if P isn't PSD.
+*/
+func QuadraticProgram(P [][]float64, q []float64, G [][]float64, h []float64, A [][]float64, b []float64, tol float64) (*QPResult, error) {
+	n, nEq := len(q), len(A)
+
+	// stack constraints as l <= Cx <= u, equalities first
+	var C [][]float64
+	var lower, upper, rhoScale []float64
+	for i := range A {
+		C = append(C, A[i])
+		lower = append(lower, b[i])
+		upper = append(upper, b[i])
+		rhoScale = append(rhoScale, 1e3)
+	}
+	for i := range G {
+		C = append(C, G[i])
+		lower = append(lower, math.Inf(-1))
+		upper = append(upper, h[i])
+		rhoScale = append(rhoScale, 1)
+	}
+	m := len(C)
+
+	rho := 0.1
+	rhos := make([]float64, m)
+	factor := func() ([][]float64, error) {
+		K := matrix.Copy(P)
+		for i := range C {
+			rhos[i] = rho * rhoScale[i]
+			for j := 0; j < n; j++ {
+				for k := 0; k < n; k++ {
+					K[j][k] += rhos[i] * C[i][j] * C[i][k]
+				}
+			}
+		}
+		for j := 0; j < n; j++ {
+			K[j][j] += qpSigma
+		}
+		return matrix.Cholesky(K)
+	}
+
+	L, err := factor()
+	if err != nil {
+		return nil, err
+	}
+
+	x := make([]float64, n)
+	z := make([]float64, m)
+	y := make([]float64, m)
+	rhs := make([]float64, n)
+
+	result := new(QPResult)
+	result.Status = IterationLimit
+
+	for iter := 0; iter < qpMaxIter; iter++ {
+		result.Iterations++
+
+		// x~ = (P + sigma I + C'RC)^-1 (sigma x - q + C'(Rz - y))
+		for j := 0; j < n; j++ {
+			rhs[j] = qpSigma*x[j] - q[j]
+		}
+		for i := range C {
+			w := rhos[i]*z[i] - y[i]
+			for j := 0; j < n; j++ {
+				rhs[j] += C[i][j] * w
+			}
+		}
+		xTilde := matrix.CholeskySolve(L, rhs)
+
+		xPrev := make([]float64, n)
+		yPrev := make([]float64, m)
+		copy(xPrev, x)
+		copy(yPrev, y)
+
+		for j := range x {
+			x[j] = qpAlpha*xTilde[j] + (1-qpAlpha)*x[j]
+		}
+		for i := range C {
+			zRelax := qpAlpha*matrix.VecDot(C[i], xTilde) + (1-qpAlpha)*z[i]
+			zNew := math.Min(math.Max(zRelax+y[i]/rhos[i], lower[i]), upper[i])
+			y[i] += rhos[i] * (zRelax - zNew)
+			z[i] = zNew
+		}
+
+		// primal residual ||Cx - z|| and dual residual ||Px + q + C'y||
+		Cx := matrix.VecMult(C, x)
+		Px := matrix.VecMult(P, x)
+		Cty := transMult(C, y, n)
+
+		rPrim := maxAbs(matrix.VecSub(Cx, z))
+		rDual := maxAbs(matrix.VecAdd(matrix.VecAdd(Px, q), Cty))
+		scalePrim := math.Max(maxAbs(Cx), maxAbs(z))
+		scaleDual := math.Max(math.Max(maxAbs(Px), maxAbs(Cty)), maxAbs(q))
+
+		if rPrim <= tol+tol*scalePrim && rDual <= tol+tol*scaleDual {
+			result.Status = Optimal
+			break
+		}
+
+		if qpPrimalInfeasible(C, lower, upper, matrix.VecSub(y, yPrev), n, tol) {
+			result.Status = Infeasible
+			break
+		}
+
+		if qpDualInfeasible(P, q, C, lower, upper, matrix.VecSub(x, xPrev), tol) {
+			result.Status = Unbounded
+			break
+		}
+
+		// rebalance rho when the residuals drift far apart
+		if iter%50 == 49 && m > 0 {
+			ratio := math.Sqrt((rPrim / math.Max(scalePrim, 1e-10)) /
+				math.Max(rDual/math.Max(scaleDual, 1e-10), 1e-10))
+			if ratio > 5 || ratio < 0.2 {
+				rho = math.Min(math.Max(rho*ratio, 1e-6), 1e6)
+				if L, err = factor(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	switch result.Status {
+	case Infeasible:
+		return result, ErrInfeasible
+	case Unbounded:
+		return result, ErrUnbounded
+	}
+
+	result.X = x
+	result.Value = 0.5*matrix.VecDot(x, matrix.VecMult(P, x)) + matrix.VecDot(q, x)
+	result.Equality = y[:nEq]
+	result.Inequality = y[nEq:]
+	for i, v := range result.Inequality {
+		result.Inequality[i] = math.Max(v, 0)
+	}
+
+	if result.Status == IterationLimit {
+		return result, ErrMaxIterations
+	}
+	return result, nil
+}
+
+/*
+ Checks whether dy certifies primal infeasibility:
+     C'dy ~= 0 and u'max(dy, 0) + l'min(dy, 0) < 0
+*/
+func qpPrimalInfeasible(C [][]float64, lower, upper, dy []float64, n int, tol float64) bool {
+	norm := maxAbs(dy)
+	if norm <= tol {
+		return false
+	}
+
+	if maxAbs(transMult(C, dy, n)) > tol*norm {
+		return false
+	}
+
+	support := 0.0
+	for i, v := range dy {
+		if v > 0 {
+			support += upper[i] * v
+		} else if v < 0 {
+			if math.IsInf(lower[i], -1) {
+				return false
+			}
+			support += lower[i] * v
+		}
+	}
+
+	return support < -tol*norm
+}
+
+/*
+ Checks whether dx certifies dual infeasibility (an unbounded problem):
+     Pdx ~= 0, q'dx < 0 and Cdx is a recession direction of [l, u]
+*/
+func qpDualInfeasible(P [][]float64, q []float64, C [][]float64, lower, upper, dx []float64, tol float64) bool {
+	norm := maxAbs(dx)
+	if norm <= tol {
+		return false
+	}
+
+	if maxAbs(matrix.VecMult(P, dx)) > tol*norm || matrix.VecDot(q, dx) >= -tol*norm {
+		return false
+	}
+
+	for i := range C {
+		v := matrix.VecDot(C[i], dx)
+		if !math.IsInf(upper[i], 1) && v > tol*norm {
+			return false
+		}
+		if !math.IsInf(lower[i], -1) && v < -tol*norm {
+			return false
+		}
+	}
+
+	return true
+}
+
+// C'y for a possibly empty C with n columns
+func transMult(C [][]float64, y []float64, n int) []float64 {
+	Cty := make([]float64, n)
+	for i := range C {
+		for j := 0; j < n; j++ {
+			Cty[j] += C[i][j] * y[i]
+		}
+	}
+	return Cty
+}
@@ -0,0 +1,40 @@
+package optimization
+
+import (
+	"testing"
+)
+
+func TestQuadraticProgram(t *testing.T) {
+	P := [][]float64{{4, 1}, {1, 2}}
+	q := []float64{1, 1}
+	A := [][]float64{{1, 1}}
+	b := []float64{1}
+	G := [][]float64{{-1, 0}, {0, -1}, {0, 1}}
+	h := []float64{0, 0, 0.7}
+
+	result, err := QuadraticProgram(P, q, G, h, A, b, 1e-8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "x", result.X, []float64{0.3, 0.7}, 1e-5)
+	assertClose(t, "equality", result.Equality, []float64{-2.9}, 1e-4)
+	assertClose(t, "inequality", result.Inequality, []float64{0, 0, 0.2}, 1e-4)
+	assertClose(t, "value", []float64{result.Value}, []float64{1.88}, 1e-5)
+}
+
+func TestQuadraticProgramInfeasibleUnbounded(t *testing.T) {
+	P := [][]float64{{1}}
+	G := [][]float64{{1}, {-1}}
+	h := []float64{-1, 0}
+	result, err := QuadraticProgram(P, []float64{0}, G, h, nil, nil, 1e-6)
+	if err != ErrInfeasible || result.Status != Infeasible {
+		t.Errorf("expected infeasible, got %v", err)
+	}
+
+	P = [][]float64{{0}}
+	result, err = QuadraticProgram(P, []float64{-1}, nil, nil, nil, nil, 1e-6)
+	if err != ErrUnbounded || result.Status != Unbounded {
+		t.Errorf("expected unbounded, got %v", err)
+	}
+}
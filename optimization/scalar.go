@@ -0,0 +1,415 @@
+package optimization
+
+import (
+	"errors"
+	"math"
+)
+
+type fn1 func(float64) float64
+
+const (
+	maxIter     = 500
+	goldenRatio = 0.3819660112501051 // (3 - sqrt(5)) / 2
+	goldenGrow  = 1.618033988749895  // (1 + sqrt(5)) / 2
+	growLimit   = 100.0
+	machEps     = 2.220446049250313e-16
+)
+
+var (
+	ErrNoSignChange   = errors.New("function does not change sign on interval")
+	ErrMaxIterations  = errors.New("maximum iterations reached before convergence")
+	ErrZeroDerivative = errors.New("derivative is zero")
+	ErrLineSearch     = errors.New("line search failed to decrease the function")
+)
+
+/*
+ Searches downhill from the points a and b for a triplet that brackets
+ a minimum of f, i.e. a < b < c with f(b) < f(a) and f(b) < f(c).
+
+ arguments
+ ---------
+ f:    function to bracket
+ a, b: two distinct starting points
+
+ returns
+ -------
+ (a, b, c, err) where a < b < c bracket a minimum
+*/
+func Bracket(f fn1, a, b float64) (float64, float64, float64, error) {
+	fa, fb := f(a), f(b)
+	if fb > fa {
+		a, b = b, a
+		fa, fb = fb, fa
+	}
+
+	c := b + goldenGrow*(b-a)
+	fc := f(c)
+
+	for iter := 0; fb > fc; iter++ {
+		if iter >= maxIter {
+			return a, b, c, ErrMaxIterations
+		}
+
+		// parabolic extrapolation from a, b, c
+		r := (b - a) * (fb - fc)
+		q := (b - c) * (fb - fa)
+		denom := 2 * math.Copysign(math.Max(math.Abs(q-r), 1e-20), q-r)
+		u := b - ((b-c)*q-(b-a)*r)/denom
+		uLim := b + growLimit*(c-b)
+
+		var fu float64
+		switch {
+		case (b-u)*(u-c) > 0:
+			// u lies between b and c
+			fu = f(u)
+			if fu < fc {
+				a, b = b, u
+				fa, fb = fb, fu
+				return orderBracket(a, b, c)
+			} else if fu > fb {
+				c, fc = u, fu
+				return orderBracket(a, b, c)
+			}
+			u = c + goldenGrow*(c-b)
+			fu = f(u)
+		case (c-u)*(u-uLim) > 0:
+			// u lies between c and its allowed limit
+			fu = f(u)
+			if fu < fc {
+				b, c, u = c, u, u+goldenGrow*(u-c)
+				fb, fc, fu = fc, fu, f(u)
+			}
+		case (u-uLim)*(uLim-c) >= 0:
+			u = uLim
+			fu = f(u)
+		default:
+			u = c + goldenGrow*(c-b)
+			fu = f(u)
+		}
+
+		a, b, c = b, c, u
+		fa, fb, fc = fb, fc, fu
+	}
+
+	return orderBracket(a, b, c)
+}
+
+func orderBracket(a, b, c float64) (float64, float64, float64, error) {
+	if a > c {
+		a, c = c, a
+	}
+	return a, b, c, nil
+}
+
+/*
+ Minimizes f on the interval [a, b] using golden section search.
+
+ NOTE: assumes f is unimodal on [a, b]
+
+ arguments
+ ---------
+ f:    function to minimize
+ a, b: interval to search
+ tol:  absolute tolerance on the location of the minimum
+
+ returns
+ -------
+ (x, y) where
+
+ 	x: location of the minimum
+ 	y: f(x)
+*/
+func GoldenSection(f fn1, a, b, tol float64) (float64, float64) {
+	if a > b {
+		a, b = b, a
+	}
+
+	x1 := a + goldenRatio*(b-a)
+	x2 := b - goldenRatio*(b-a)
+	f1, f2 := f(x1), f(x2)
+
+	for b-a > tol {
+		if f1 < f2 {
+			b, x2, f2 = x2, x1, f1
+			x1 = a + goldenRatio*(b-a)
+			f1 = f(x1)
+		} else {
+			a, x1, f1 = x1, x2, f2
+			x2 = b - goldenRatio*(b-a)
+			f2 = f(x2)
+		}
+	}
+
+	if f1 < f2 {
+		return x1, f1
+	}
+	return x2, f2
+}
+
+/*
+ Minimizes f on the interval [a, b] using Brent's method, which
+ combines golden section steps with parabolic interpolation.
+
+ arguments
+ ---------
+ f:    function to minimize
+ a, b: interval to search (e.g. the outer points returned by Bracket)
+ tol:  absolute tolerance on the location of the minimum
+
+ returns
+ -------
+ (x, y, err) where
+
+ 	x:   location of the minimum
+ 	y:   f(x)
+ 	err: ErrMaxIterations if tolerance was not reached
+*/
+func Brent(f fn1, a, b, tol float64) (float64, float64, error) {
+	if a > b {
+		a, b = b, a
+	}
+
+	x := a + goldenRatio*(b-a)
+	w, v := x, x
+	fx := f(x)
+	fw, fv := fx, fx
+
+	// d is the current step, e the step before last
+	d, e := 0.0, 0.0
+
+	for iter := 0; iter < maxIter; iter++ {
+		m := 0.5 * (a + b)
+		tol1 := math.Sqrt(machEps)*math.Abs(x) + tol/3
+		tol2 := 2 * tol1
+
+		if math.Abs(x-m) <= tol2-0.5*(b-a) {
+			return x, fx, nil
+		}
+
+		golden := true
+		if math.Abs(e) > tol1 {
+			// try a parabolic fit through x, v, w
+			r := (x - w) * (fx - fv)
+			q := (x - v) * (fx - fw)
+			p := (x-v)*q - (x-w)*r
+			q = 2 * (q - r)
+			if q > 0 {
+				p = -p
+			} else {
+				q = -q
+			}
+
+			// accept only if the step falls inside the interval and
+			// is smaller than half the step before last
+			if math.Abs(p) < math.Abs(0.5*q*e) && p > q*(a-x) && p < q*(b-x) {
+				e = d
+				d = p / q
+				u := x + d
+				if u-a < tol2 || b-u < tol2 {
+					d = math.Copysign(tol1, m-x)
+				}
+				golden = false
+			}
+		}
+
+		if golden {
+			if x >= m {
+				e = a - x
+			} else {
+				e = b - x
+			}
+			d = goldenRatio * e
+		}
+
+		var u float64
+		if math.Abs(d) >= tol1 {
+			u = x + d
+		} else {
+			u = x + math.Copysign(tol1, d)
+		}
+		fu := f(u)
+
+		if fu <= fx {
+			if u >= x {
+				a = x
+			} else {
+				b = x
+			}
+			v, w, x = w, x, u
+			fv, fw, fx = fw, fx, fu
+		} else {
+			if u < x {
+				a = u
+			} else {
+				b = u
+			}
+			if fu <= fw || w == x {
+				v, w = w, u
+				fv, fw = fw, fu
+			} else if fu <= fv || v == x || v == w {
+				v, fv = u, fu
+			}
+		}
+	}
+
+	return x, fx, ErrMaxIterations
+}
+
+/*
+ Finds a root of f in [a, b] by bisection.
+
+ arguments
+ ---------
+ f:    function whose root to find; f(a) and f(b) must differ in sign
+ a, b: interval containing the root
+ tol:  absolute tolerance on the location of the root
+
+ returns
+ -------
+ (x, err) where f(x) ~= 0
+*/
+func Bisection(f fn1, a, b, tol float64) (float64, error) {
+	fa, fb := f(a), f(b)
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if fa*fb > 0 {
+		return 0, ErrNoSignChange
+	}
+
+	for iter := 0; iter < maxIter; iter++ {
+		m := 0.5 * (a + b)
+		fm := f(m)
+		if fm == 0 || 0.5*math.Abs(b-a) < tol {
+			return m, nil
+		}
+		if (fm > 0) == (fa > 0) {
+			a, fa = m, fm
+		} else {
+			b = m
+		}
+	}
+
+	return 0.5 * (a + b), ErrMaxIterations
+}
+
+/*
+ Finds a root of f in [a, b] using Brent's method, which combines
+ bisection, secant and inverse quadratic interpolation steps.
+
+ arguments
+ ---------
+ f:    function whose root to find; f(a) and f(b) must differ in sign
+ a, b: interval containing the root
+ tol:  absolute tolerance on the location of the root
+
+ returns
+ -------
+ (x, err) where f(x) ~= 0
+*/
+func BrentRoot(f fn1, a, b, tol float64) (float64, error) {
+	fa, fb := f(a), f(b)
+	if fa*fb > 0 {
+		return 0, ErrNoSignChange
+	}
+
+	c, fc := b, fb
+	d, e := b-a, b-a
+
+	for iter := 0; iter < maxIter; iter++ {
+		// keep the root bracketed between b and c
+		if (fb > 0 && fc > 0) || (fb < 0 && fc < 0) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+
+		// b should be the best estimate so far
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol1 := 2*machEps*math.Abs(b) + 0.5*tol
+		xm := 0.5 * (c - b)
+		if math.Abs(xm) <= tol1 || fb == 0 {
+			return b, nil
+		}
+
+		if math.Abs(e) >= tol1 && math.Abs(fa) > math.Abs(fb) {
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				// secant step
+				p = 2 * xm * s
+				q = 1 - s
+			} else {
+				// inverse quadratic interpolation
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*xm*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			}
+			p = math.Abs(p)
+
+			if 2*p < math.Min(3*xm*q-math.Abs(tol1*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = xm
+				e = d
+			}
+		} else {
+			d = xm
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol1 {
+			b += d
+		} else {
+			b += math.Copysign(tol1, xm)
+		}
+		fb = f(b)
+	}
+
+	return b, ErrMaxIterations
+}
+
+/*
+ Finds a root of f with Newton's method starting from x0.
+
+ arguments
+ ---------
+ f:   function whose root to find
+ df:  derivative of f
+ x0:  initial guess
+ tol: stop once the Newton step is smaller than tol
+
+ returns
+ -------
+ (x, err) where f(x) ~= 0
+*/
+func Newton(f, df fn1, x0, tol float64) (float64, error) {
+	x := x0
+	for iter := 0; iter < maxIter; iter++ {
+		dy := df(x)
+		if dy == 0 {
+			return x, ErrZeroDerivative
+		}
+
+		step := f(x) / dy
+		x -= step
+		if math.Abs(step) < tol {
+			return x, nil
+		}
+	}
+
+	return x, ErrMaxIterations
+}
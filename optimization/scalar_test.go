@@ -0,0 +1,61 @@
+package optimization
+
+import (
+	"math"
+	"testing"
+)
+
+func parabola(x float64) float64 {
+	return (x-2)*(x-2) + 1
+}
+
+func TestBracket(t *testing.T) {
+	a, b, c, err := Bracket(parabola, -10, -9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(a < b && b < c) || parabola(b) > parabola(a) || parabola(b) > parabola(c) {
+		t.Errorf("(%.3f, %.3f, %.3f) does not bracket a minimum", a, b, c)
+	}
+}
+
+func TestGoldenSection(t *testing.T) {
+	x, y := GoldenSection(parabola, -5, 5, 1e-8)
+	if math.Abs(x-2) > 1e-6 || math.Abs(y-1) > 1e-10 {
+		t.Errorf("golden section found (%.6f, %.6f), expected (2, 1)", x, y)
+	}
+}
+
+func TestBrent(t *testing.T) {
+	x, _, err := Brent(math.Cos, 0, 2*math.Pi, 1e-8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(x-math.Pi) > 1e-6 {
+		t.Errorf("brent found %.6f, expected pi", x)
+	}
+}
+
+func TestRootFinders(t *testing.T) {
+	f := func(x float64) float64 { return x*x - 2 }
+	df := func(x float64) float64 { return 2 * x }
+
+	x1, err1 := Bisection(f, 0, 2, 1e-10)
+	x2, err2 := BrentRoot(f, 0, 2, 1e-10)
+	x3, err3 := Newton(f, df, 1, 1e-10)
+	for _, err := range []error{err1, err2, err3} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, x := range []float64{x1, x2, x3} {
+		if math.Abs(x-math.Sqrt2) > 1e-8 {
+			t.Errorf("root %.10f, expected sqrt(2)", x)
+		}
+	}
+
+	if _, err := BrentRoot(f, 2, 3, 1e-10); err != ErrNoSignChange {
+		t.Errorf("expected ErrNoSignChange, got %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package optimization
+
+import (
+	"errors"
+	"math"
+)
+
+type Status int
+
+const (
+	Optimal Status = iota
+	Infeasible
+	Unbounded
+	IterationLimit
+)
+
+const lpTol = 1e-9
+
+var (
+	ErrInfeasible = errors.New("problem is infeasible")
+	ErrUnbounded  = errors.New("problem is unbounded")
+)
+
+func (s Status) String() string {
+	switch s {
+	case Optimal:
+		return "optimal"
+	case Infeasible:
+		return "infeasible"
+	case Unbounded:
+		return "unbounded"
+	case IterationLimit:
+		return "iteration limit"
+	}
+	return "unknown"
+}
+
+type LPResult struct {
+	X      []float64 // primal solution
+	Value  float64   // objective value c'X
+	Dual   []float64 // dual values, d(Value)/d(rhs) for each constraint row
+	Status Status
+}
+
+/*
+ Solves the linear program
+
+     minimize   c'x
+     subject to Gx <= h
+                Ax  = b
+                 x >= 0
+
+ with the two-phase simplex method.
+
+ arguments
+ ---------
+ c:    objective coefficients
+ G, h: inequality constraints (may be nil)
+ A, b: equality constraints (may be nil)
+
+ returns
+ -------
+ (result, err) where
+     result.Dual holds the inequality duals followed by the equality
+     duals; inequality duals are <= 0.
+     err is ErrInfeasible or ErrUnbounded when no optimum exists.
+*/
+func Simplex(c []float64, G [][]float64, h []float64, A [][]float64, b []float64) (*LPResult, error) {
+	n, nIneq := len(c), len(G)
+
+	// add a slack variable to each inequality to get standard form
+	cStd := make([]float64, n+nIneq)
+	copy(cStd, c)
+
+	AStd := make([][]float64, 0, nIneq+len(A))
+	bStd := make([]float64, 0, nIneq+len(A))
+	for i := range G {
+		row := make([]float64, n+nIneq)
+		copy(row, G[i])
+		row[n+i] = 1
+		AStd = append(AStd, row)
+		bStd = append(bStd, h[i])
+	}
+	for i := range A {
+		row := make([]float64, n+nIneq)
+		copy(row, A[i])
+		AStd = append(AStd, row)
+		bStd = append(bStd, b[i])
+	}
+
+	result, err := SimplexStandard(cStd, AStd, bStd)
+	if result.X != nil {
+		result.X = result.X[:n]
+	}
+	return result, err
+}
+
+/*
+ Solves the standard form linear program
+
+     minimize   c'x
+     subject to Ax = b
+                 x >= 0
+
+ with the two-phase simplex method using Bland's rule to avoid cycling.
+
+ returns
+ -------
+ (result, err) where
+     result.Dual holds one dual value per row of A
+     err is ErrInfeasible or ErrUnbounded when no optimum exists.
+*/
+func SimplexStandard(c []float64, A [][]float64, b []float64) (*LPResult, error) {
+	m, n := len(A), len(c)
+	result := new(LPResult)
+
+	// tableau columns: n structural, m artificial, rhs
+	N := n + m
+	T := make([][]float64, m)
+	sign := make([]float64, m)
+	basis := make([]int, m)
+	for i := range T {
+		T[i] = make([]float64, N+1)
+		sign[i] = 1
+		if b[i] < 0 {
+			sign[i] = -1
+		}
+		for j := 0; j < n; j++ {
+			T[i][j] = sign[i] * A[i][j]
+		}
+		T[i][n+i] = 1
+		T[i][N] = sign[i] * b[i]
+		basis[i] = n + i
+	}
+
+	structural := func(j int) bool { return j < n }
+	anyColumn := func(j int) bool { return true }
+
+	// phase 1: minimize the sum of the artificial variables
+	obj := make([]float64, N+1)
+	for j := 0; j <= N; j++ {
+		if j >= n && j < N {
+			continue
+		}
+		for i := range T {
+			obj[j] -= T[i][j]
+		}
+	}
+
+	if status := runSimplex(T, obj, basis, anyColumn); status != Optimal {
+		result.Status = status
+		return result, ErrMaxIterations
+	}
+
+	if -obj[N] > lpTol*math.Max(1, maxAbs(b)) {
+		result.Status = Infeasible
+		return result, ErrInfeasible
+	}
+
+	// drive any remaining artificial variables out of the basis; rows
+	// where that isn't possible are redundant and keep a zero artificial
+	for i, j := range basis {
+		if j < n {
+			continue
+		}
+		for k := 0; k < n; k++ {
+			if math.Abs(T[i][k]) > lpTol {
+				pivot(T, obj, basis, i, k)
+				break
+			}
+		}
+	}
+
+	// phase 2: reduced costs of the real objective for the current basis
+	for j := 0; j <= N; j++ {
+		obj[j] = 0
+		if j < n {
+			obj[j] = c[j]
+		}
+		for i, k := range basis {
+			if k < n {
+				obj[j] -= c[k] * T[i][j]
+			}
+		}
+	}
+
+	status := runSimplex(T, obj, basis, structural)
+	result.Status = status
+	switch status {
+	case Unbounded:
+		return result, ErrUnbounded
+	case IterationLimit:
+		return result, ErrMaxIterations
+	}
+
+	result.X = make([]float64, n)
+	for i, j := range basis {
+		if j < n {
+			result.X[j] = T[i][N]
+		}
+	}
+	result.Value = -obj[N]
+
+	// y' = c_B' B^-1, where B^-1 sits in the artificial columns
+	result.Dual = make([]float64, m)
+	for r := 0; r < m; r++ {
+		for i, k := range basis {
+			if k < n {
+				result.Dual[r] += c[k] * T[i][n+r]
+			}
+		}
+		result.Dual[r] *= sign[r]
+	}
+
+	return result, nil
+}
+
+/*
+ Runs simplex iterations on tableau T with reduced cost row obj until
+ no allowed column has a negative reduced cost.
+*/
+func runSimplex(T [][]float64, obj []float64, basis []int, allowed func(int) bool) Status {
+	N := len(obj) - 1
+	limit := maxIter * (len(T) + N + 1)
+
+	for iter := 0; iter < limit; iter++ {
+		// Bland's rule: lowest index column with negative reduced cost
+		enter := -1
+		for j := 0; j < N; j++ {
+			if allowed(j) && obj[j] < -lpTol {
+				enter = j
+				break
+			}
+		}
+		if enter == -1 {
+			return Optimal
+		}
+
+		// ratio test, ties broken by lowest basis index
+		leave := -1
+		minRatio := math.Inf(1)
+		for i := range T {
+			if T[i][enter] > lpTol {
+				ratio := T[i][N] / T[i][enter]
+				if leave == -1 || ratio < minRatio-lpTol ||
+					(ratio < minRatio+lpTol && basis[i] < basis[leave]) {
+					minRatio = ratio
+					leave = i
+				}
+			}
+		}
+		if leave == -1 {
+			return Unbounded
+		}
+
+		pivot(T, obj, basis, leave, enter)
+	}
+
+	return IterationLimit
+}
+
+func pivot(T [][]float64, obj []float64, basis []int, row, col int) {
+	p := T[row][col]
+	for j := range T[row] {
+		T[row][j] /= p
+	}
+
+	eliminate := func(r []float64) {
+		factor := r[col]
+		if factor == 0 {
+			return
+		}
+		for j := range r {
+			r[j] -= factor * T[row][j]
+		}
+	}
+
+	for i := range T {
+		if i != row {
+			eliminate(T[i])
+		}
+	}
+	eliminate(obj)
+
+	basis[row] = col
+}
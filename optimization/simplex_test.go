@@ -0,0 +1,64 @@
+package optimization
+
+import (
+	"math"
+	"testing"
+)
+
+func assertClose(t *testing.T, name string, got, expected []float64, tol float64) {
+	for i := range expected {
+		if math.Abs(got[i]-expected[i]) > tol {
+			t.Errorf("%s = %v, expected %v", name, got, expected)
+			return
+		}
+	}
+}
+
+func TestSimplex(t *testing.T) {
+	// maximize 3x + 5y
+	c := []float64{-3, -5}
+	G := [][]float64{{1, 0}, {0, 2}, {3, 2}}
+	h := []float64{4, 12, 18}
+
+	result, err := Simplex(c, G, h, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "x", result.X, []float64{2, 6}, 1e-9)
+	assertClose(t, "dual", result.Dual, []float64{0, -1.5, -1}, 1e-9)
+	if math.Abs(result.Value+36) > 1e-9 {
+		t.Errorf("value = %.3f, expected -36", result.Value)
+	}
+}
+
+func TestSimplexEquality(t *testing.T) {
+	c := []float64{1, 1}
+	A := [][]float64{{1, 2}, {2, 4}}
+	b := []float64{4, 8}
+
+	result, err := SimplexStandard(c, A, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClose(t, "x", result.X, []float64{0, 2}, 1e-9)
+	if math.Abs(result.Value-2) > 1e-9 {
+		t.Errorf("value = %.3f, expected 2", result.Value)
+	}
+}
+
+func TestSimplexInfeasibleUnbounded(t *testing.T) {
+	G := [][]float64{{1}, {-1}}
+	h := []float64{1, -2}
+	result, err := Simplex([]float64{1}, G, h, nil, nil)
+	if err != ErrInfeasible || result.Status != Infeasible {
+		t.Errorf("expected infeasible, got %v (%s)", err, result.Status)
+	}
+
+	G = [][]float64{{1, -1}}
+	h = []float64{1}
+	result, err = Simplex([]float64{-1, 0}, G, h, nil, nil)
+	if err != ErrUnbounded || result.Status != Unbounded {
+		t.Errorf("expected unbounded, got %v (%s)", err, result.Status)
+	}
+}
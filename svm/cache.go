@@ -0,0 +1,73 @@
+package svm
+
+import (
+	"container/list"
+
+	"github.com/emef/go.ml/kernel"
+)
+
+/*
+ least recently used cache of kernel matrix rows, K[i][j] = k(X[i], X[j]).
+ SMO only ever needs the two rows of its working set, and the same
+ samples tend to be picked again and again, so caching rows avoids most
+ kernel evaluations without storing the full n x n matrix.
+*/
+type kernelCache struct {
+	k        kernel.Kernel
+	X        [][]float64
+	capacity int                   // most rows kept
+	rows     map[int]*list.Element // sample index -> element holding its row
+	order    *list.List            // most recently used at the front
+	diag     []float64             // K[i][i], always kept
+	misses   int                   // rows computed so far
+}
+
+type cachedRow struct {
+	index int
+	row   []float64
+}
+
+func newKernelCache(k kernel.Kernel, X [][]float64, capacity int) *kernelCache {
+	if capacity < 2 {
+		capacity = 2
+	}
+
+	cache := new(kernelCache)
+	cache.k = k
+	cache.X = X
+	cache.capacity = capacity
+	cache.rows = make(map[int]*list.Element)
+	cache.order = list.New()
+	cache.diag = make([]float64, len(X))
+	for i := range X {
+		cache.diag[i] = k(X[i], X[i])
+	}
+	return cache
+}
+
+// row i of the kernel matrix; the slice must not be modified
+func (cache *kernelCache) row(i int) []float64 {
+	if e, ok := cache.rows[i]; ok {
+		cache.order.MoveToFront(e)
+		return e.Value.(*cachedRow).row
+	}
+
+	var entry *cachedRow
+	if cache.order.Len() >= cache.capacity {
+		// reuse the least recently used row's storage
+		e := cache.order.Back()
+		entry = cache.order.Remove(e).(*cachedRow)
+		delete(cache.rows, entry.index)
+	} else {
+		entry = &cachedRow{row: make([]float64, len(cache.X))}
+	}
+
+	entry.index = i
+	for j := range cache.X {
+		entry.row[j] = cache.k(cache.X[i], cache.X[j])
+	}
+	cache.rows[i] = cache.order.PushFront(entry)
+	cache.misses++
+
+	return entry.row
+}
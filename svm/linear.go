@@ -0,0 +1,426 @@
+package svm
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+const (
+	Hinge                     = "hinge"                       // max(0, 1 - y f(x))
+	SquaredHinge              = "squared_hinge"               // max(0, 1 - y f(x))^2
+	EpsilonInsensitive        = "epsilon_insensitive"         // max(0, |y - f(x)| - epsilon)
+	SquaredEpsilonInsensitive = "squared_epsilon_insensitive" // max(0, |y - f(x)| - epsilon)^2
+)
+
+/*
+ linear support vector classifier, minimizing
+
+     1/2 ||w||^2 + C sum_i loss(y_i (w'x_i + b))
+
+ in the dual by coordinate descent (Hsieh et al., 2008). Each step
+ touches a single sample, and only its non-zero features, so fitting
+ scales to high-dimensional sparse problems. As in liblinear the
+ intercept is learned as the weight of a constant feature
+ InterceptScaling and so is also penalized. More than two classes are
+ handled one-vs-rest.
+*/
+type LinearSVC struct {
+	C                float64     // inverse regularization strength
+	Loss             string      // Hinge or SquaredHinge
+	FitIntercept     bool        // whether to fit an intercept
+	InterceptScaling float64     // value of the constant feature for the intercept
+	Tol              float64     // projected gradient gap at convergence, relative to the first pass
+	MaxIter          int         // maximum number of passes over the data
+	Classes          []float64   // sorted distinct labels seen by Fit
+	Coef             [][]float64 // one row per binary problem (a single row for two classes)
+	Intercept        []float64   // intercept of each binary problem
+	NIter            int         // most passes used by any binary problem
+}
+
+/*
+ linear SVM classifier constructor
+
+ arguments
+ ---------
+   C:            inverse regularization strength, larger fits the
+                 training data more closely
+   loss:         Hinge ("hinge") or SquaredHinge ("squared_hinge")
+   fitIntercept: whether to fit an intercept
+*/
+func NewLinearSVC(C float64, loss string, fitIntercept bool) (*LinearSVC, error) {
+	if loss != Hinge && loss != SquaredHinge {
+		return nil, errors.New("unknown loss")
+	}
+	if C <= 0 {
+		return nil, errors.New("C must be positive")
+	}
+
+	model := new(LinearSVC)
+	model.C = C
+	model.Loss = loss
+	model.FitIntercept = fitIntercept
+	model.InterceptScaling = 1
+	model.Tol = 0.1
+	model.MaxIter = 1000
+	return model, nil
+}
+
+// fit the model with samples (X) and class labels (y), which may be any floats
+func (model *LinearSVC) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	model.Classes = datasets.Classes(y)
+	if len(model.Classes) < 2 {
+		return errors.New("need at least two classes")
+	}
+
+	rows := sparseRows(X, model.bias())
+
+	// the positive class of each binary problem
+	positive := model.Classes[1:]
+	if len(model.Classes) > 2 {
+		positive = model.Classes
+	}
+
+	model.Coef = make([][]float64, len(positive))
+	model.Intercept = make([]float64, len(positive))
+	model.NIter = 0
+	converged := true
+
+	for k, class := range positive {
+		sign := make([]float64, len(y))
+		for i := range y {
+			sign[i] = -1
+			if y[i] == class {
+				sign[i] = 1
+			}
+		}
+
+		w, iter, ok := model.solve(rows, sign, len(X[0]))
+		model.Coef[k] = w[:len(X[0])]
+		if model.FitIntercept {
+			model.Intercept[k] = w[len(X[0])] * model.InterceptScaling
+		}
+		if iter > model.NIter {
+			model.NIter = iter
+		}
+		converged = converged && ok
+	}
+
+	if !converged {
+		return errors.New("dual coordinate descent did not converge")
+	}
+	return nil
+}
+
+/*
+ dual coordinate descent for one binary problem with labels +/-1
+
+ returns
+ -------
+   (w, iterations, converged) where w has an extra trailing weight for
+   the constant feature
+*/
+func (model *LinearSVC) solve(rows []sparseRow, y []float64, p int) ([]float64, int, bool) {
+	n := len(rows)
+
+	// hinge: 0 <= alpha <= C; squared hinge: alpha >= 0 with a diagonal shift
+	upper, diag := model.C, 0.0
+	if model.Loss == SquaredHinge {
+		upper, diag = math.Inf(1), 0.5/model.C
+	}
+
+	w := make([]float64, p+1)
+	alpha := make([]float64, n)
+	Qii := make([]float64, n)
+	for i, row := range rows {
+		Qii[i] = row.squaredNorm() + diag
+	}
+
+	// as in liblinear, stop once the spread of the projected gradient has
+	// shrunk by Tol relative to the first pass
+	gap0 := 0.0
+	for iter := 1; iter <= model.MaxIter; iter++ {
+		maxPG, minPG := math.Inf(-1), math.Inf(1)
+
+		for _, i := range rand.Perm(n) {
+			G := y[i]*rows[i].dot(w) - 1 + diag*alpha[i]
+
+			// projected gradient
+			PG := G
+			if alpha[i] == 0 {
+				PG = math.Min(G, 0)
+			} else if alpha[i] == upper {
+				PG = math.Max(G, 0)
+			}
+			maxPG = math.Max(maxPG, PG)
+			minPG = math.Min(minPG, PG)
+
+			if PG != 0 && Qii[i] > 0 {
+				old := alpha[i]
+				alpha[i] = math.Min(math.Max(old-G/Qii[i], 0), upper)
+				rows[i].axpy((alpha[i]-old)*y[i], w)
+			}
+		}
+
+		if iter == 1 {
+			gap0 = maxPG - minPG
+		}
+		if maxPG-minPG <= model.Tol*gap0 {
+			return w, iter, true
+		}
+	}
+
+	return w, model.MaxIter, false
+}
+
+// signed distance of samples (X) from each binary problem's hyperplane
+func (model LinearSVC) DecisionFunction(X [][]float64) [][]float64 {
+	scores := make([][]float64, len(X))
+	for i := range X {
+		scores[i] = make([]float64, len(model.Coef))
+		for k := range model.Coef {
+			scores[i][k] = matrix.VecDot(model.Coef[k], X[i]) + model.Intercept[k]
+		}
+	}
+	return scores
+}
+
+// predict class labels for samples (X)
+func (model LinearSVC) Predict(X [][]float64) []float64 {
+	y := make([]float64, len(X))
+	for i, score := range model.DecisionFunction(X) {
+		if len(score) == 1 {
+			y[i] = model.Classes[0]
+			if score[0] > 0 {
+				y[i] = model.Classes[1]
+			}
+		} else {
+			y[i] = model.Classes[matrix.VecArgMax(score)]
+		}
+	}
+	return y
+}
+
+// accuracy of the predictions for X
+func (model LinearSVC) Score(X [][]float64, y []float64) float64 {
+	return metrics.Accuracy(model.Predict(X), y)
+}
+
+func (model LinearSVC) bias() float64 {
+	if model.FitIntercept {
+		return model.InterceptScaling
+	}
+	return 0
+}
+
+/*
+ linear support vector regression, minimizing
+
+     1/2 ||w||^2 + C sum_i loss(y_i - w'x_i - b)
+
+ with an epsilon-insensitive loss, in the dual by coordinate descent
+ (Ho and Lin, 2012). The intercept is handled as in LinearSVC.
+*/
+type LinearSVR struct {
+	C                float64   // inverse regularization strength
+	Epsilon          float64   // residuals within Epsilon cost nothing
+	Loss             string    // EpsilonInsensitive or SquaredEpsilonInsensitive
+	FitIntercept     bool      // whether to fit an intercept
+	InterceptScaling float64   // value of the constant feature for the intercept
+	Tol              float64   // total dual violation at convergence, relative to the first pass
+	MaxIter          int       // maximum number of passes over the data
+	Coef             []float64 // fitted coefficients, one per feature
+	Intercept        float64   // fitted intercept, 0 unless FitIntercept
+	NIter            int       // passes used by the last fit
+}
+
+/*
+ linear SVM regression constructor
+
+ arguments
+ ---------
+   C:            inverse regularization strength
+   epsilon:      half width of the insensitive tube around the fit
+   loss:         EpsilonInsensitive ("epsilon_insensitive") or
+                 SquaredEpsilonInsensitive ("squared_epsilon_insensitive")
+   fitIntercept: whether to fit an intercept
+*/
+func NewLinearSVR(C, epsilon float64, loss string, fitIntercept bool) (*LinearSVR, error) {
+	if loss != EpsilonInsensitive && loss != SquaredEpsilonInsensitive {
+		return nil, errors.New("unknown loss")
+	}
+	if C <= 0 {
+		return nil, errors.New("C must be positive")
+	}
+	if epsilon < 0 {
+		return nil, errors.New("epsilon must be non-negative")
+	}
+
+	model := new(LinearSVR)
+	model.C = C
+	model.Epsilon = epsilon
+	model.Loss = loss
+	model.FitIntercept = fitIntercept
+	model.InterceptScaling = 1
+	model.Tol = 1e-3
+	model.MaxIter = 1000
+	return model, nil
+}
+
+// fit the model with samples (X) and responses (y)
+func (model *LinearSVR) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	bias := 0.0
+	if model.FitIntercept {
+		bias = model.InterceptScaling
+	}
+	rows := sparseRows(X, bias)
+	n, p := len(X), len(X[0])
+
+	// epsilon-insensitive: -C <= beta <= C; squared: a diagonal shift instead
+	upper, diag := model.C, 0.0
+	if model.Loss == SquaredEpsilonInsensitive {
+		upper, diag = math.Inf(1), 0.5/model.C
+	}
+
+	w := make([]float64, p+1)
+	beta := make([]float64, n)
+	Qii := make([]float64, n)
+	for i, row := range rows {
+		Qii[i] = row.squaredNorm()
+	}
+
+	converged, initial := false, 0.0
+	for model.NIter = 1; model.NIter <= model.MaxIter; model.NIter++ {
+		violation := 0.0
+
+		for _, i := range rand.Perm(n) {
+			G := rows[i].dot(w) - y[i] + diag*beta[i]
+			H := Qii[i] + diag
+			Gp, Gn := G+model.Epsilon, G-model.Epsilon
+
+			// optimality violation of the subproblem in beta_i
+			switch {
+			case beta[i] == 0:
+				violation += math.Max(0, math.Max(-Gp, Gn))
+			case beta[i] >= upper:
+				violation += math.Max(0, Gp)
+			case beta[i] <= -upper:
+				violation += math.Max(0, -Gn)
+			case beta[i] > 0:
+				violation += math.Abs(Gp)
+			default:
+				violation += math.Abs(Gn)
+			}
+
+			if H <= 0 {
+				continue
+			}
+
+			// newton step on the piecewise quadratic in beta_i
+			var d float64
+			if Gp < H*beta[i] {
+				d = -Gp / H
+			} else if Gn > H*beta[i] {
+				d = -Gn / H
+			} else {
+				d = -beta[i]
+			}
+
+			old := beta[i]
+			beta[i] = math.Min(math.Max(old+d, -upper), upper)
+			if beta[i] != old {
+				rows[i].axpy(beta[i]-old, w)
+			}
+		}
+
+		// as in liblinear, stop relative to the violation of the first pass
+		if model.NIter == 1 {
+			initial = violation
+		}
+		if violation <= model.Tol*initial {
+			converged = true
+			break
+		}
+	}
+
+	model.Coef = w[:p]
+	model.Intercept = w[p] * bias
+
+	if !converged {
+		return errors.New("dual coordinate descent did not converge")
+	}
+	return nil
+}
+
+// predict responses for samples (X)
+func (model LinearSVR) Predict(X [][]float64) []float64 {
+	y := make([]float64, len(X))
+	for i := range X {
+		y[i] = matrix.VecDot(model.Coef, X[i]) + model.Intercept
+	}
+	return y
+}
+
+// coefficient of determination (R^2) of the predictions for X
+func (model LinearSVR) Score(X [][]float64, y []float64) float64 {
+	return metrics.R2(model.Predict(X), y)
+}
+
+// non-zero entries of a sample, with the constant feature (if any) last
+type sparseRow struct {
+	index []int
+	value []float64
+}
+
+func sparseRows(X [][]float64, bias float64) []sparseRow {
+	p := len(X[0])
+	rows := make([]sparseRow, len(X))
+	for i, x := range X {
+		for j, v := range x {
+			if v != 0 {
+				rows[i].index = append(rows[i].index, j)
+				rows[i].value = append(rows[i].value, v)
+			}
+		}
+		if bias != 0 {
+			rows[i].index = append(rows[i].index, p)
+			rows[i].value = append(rows[i].value, bias)
+		}
+	}
+	return rows
+}
+
+func (row sparseRow) dot(w []float64) float64 {
+	s := 0.0
+	for k, j := range row.index {
+		s += row.value[k] * w[j]
+	}
+	return s
+}
+
+// w += a * row
+func (row sparseRow) axpy(a float64, w []float64) {
+	for k, j := range row.index {
+		w[j] += a * row.value[k]
+	}
+}
+
+func (row sparseRow) squaredNorm() float64 {
+	s := 0.0
+	for _, v := range row.value {
+		s += v * v
+	}
+	return s
+}
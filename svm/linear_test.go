@@ -0,0 +1,164 @@
+package svm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/metrics"
+)
+
+// gaussian blobs around one center per class
+func blobs(n int, centers [][]float64, labels []float64, spread float64) ([][]float64, []float64) {
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		k := i % len(centers)
+		X[i] = make([]float64, len(centers[k]))
+		for j := range X[i] {
+			X[i][j] = centers[k][j] + spread*rand.NormFloat64()
+		}
+		y[i] = labels[k]
+	}
+	return X, y
+}
+
+// scale each column to zero mean and unit variance, in place
+func standardize(X [][]float64) {
+	n := float64(len(X))
+	for j := range X[0] {
+		mean, sq := 0.0, 0.0
+		for i := range X {
+			mean += X[i][j] / n
+			sq += X[i][j] * X[i][j] / n
+		}
+		std := math.Sqrt(sq - mean*mean)
+		for i := range X {
+			X[i][j] -= mean
+			if std > 0 {
+				X[i][j] /= std
+			}
+		}
+	}
+}
+
+func TestLinearSVC(t *testing.T) {
+	X, y := blobs(200, [][]float64{{2, 2}, {-2, -1}}, []float64{3, -7}, 0.7)
+
+	for _, loss := range []string{Hinge, SquaredHinge} {
+		model, err := NewLinearSVC(1, loss, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := model.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if score := model.Score(X, y); score < 0.99 {
+			t.Errorf("%s: training accuracy %.3f", loss, score)
+		}
+
+		if loss != Hinge {
+			continue
+		}
+
+		// free support vectors of the hinge loss sit right on the margin
+		minMargin := math.Inf(1)
+		for i, score := range model.DecisionFunction(X) {
+			sign := -1.0
+			if y[i] == model.Classes[1] {
+				sign = 1
+			}
+			minMargin = math.Min(minMargin, math.Abs(sign*score[0]-1))
+		}
+		if minMargin > 0.05 {
+			t.Errorf("%s: no sample on the margin, closest %.3f", loss, minMargin)
+		}
+	}
+
+	if _, err := NewLinearSVC(1, "log", true); err == nil {
+		t.Errorf("expected error for unknown loss")
+	}
+}
+
+func TestLinearSVCMulticlass(t *testing.T) {
+	centers := [][]float64{{0, 4}, {4, 0}, {-4, -4}}
+	X, y := blobs(300, centers, []float64{0, 1, 2}, 0.7)
+
+	model, _ := NewLinearSVC(1, SquaredHinge, true)
+	model.Fit(X, y)
+	if len(model.Coef) != 3 {
+		t.Fatalf("expected one-vs-rest coefficients for 3 classes, got %d", len(model.Coef))
+	}
+	if score := model.Score(X, y); score < 0.98 {
+		t.Errorf("training accuracy %.3f", score)
+	}
+}
+
+func TestLinearSVCSparse(t *testing.T) {
+	// 1000 features with 10 non-zeros per sample; the label is whether
+	// any of them is among the first 100 features (about 65% of samples)
+	rng := rand.New(rand.NewSource(7))
+	n, p := 2500, 1000
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = make([]float64, p)
+		for k := 0; k < 10; k++ {
+			j := rng.Intn(p)
+			X[i][j] = 1
+			if j < 100 {
+				y[i] = 1
+			}
+		}
+	}
+
+	model, _ := NewLinearSVC(1, Hinge, true)
+	if err := model.Fit(X[:2000], y[:2000]); err != nil {
+		t.Fatal(err)
+	}
+	if acc := metrics.Accuracy(model.Predict(X[2000:]), y[2000:]); acc < 0.9 {
+		t.Errorf("held out accuracy %.3f", acc)
+	}
+}
+
+func TestLinearSVCBreastCancer(t *testing.T) {
+	X, y := datasets.Load("cancer")
+	datasets.RandomShuffle(X, y)
+	standardize(X)
+
+	model, _ := NewLinearSVC(0.1, SquaredHinge, true)
+	model.Fit(X[:400], y[:400])
+	if acc := model.Score(X[400:], y[400:]); acc < 0.93 {
+		t.Errorf("held out accuracy %.3f", acc)
+	}
+}
+
+func TestLinearSVR(t *testing.T) {
+	n := 200
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		X[i] = []float64{rand.NormFloat64(), rand.NormFloat64()}
+		y[i] = 1 + 2*X[i][0] - 3*X[i][1] + 0.05*rand.NormFloat64()
+	}
+
+	for _, loss := range []string{EpsilonInsensitive, SquaredEpsilonInsensitive} {
+		model, err := NewLinearSVR(10, 0.1, loss, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the dual of the absolute loss converges slowly for large C
+		model.MaxIter = 100000
+		if err := model.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(model.Coef[0]-2) > 0.05 || math.Abs(model.Coef[1]+3) > 0.05 ||
+			math.Abs(model.Intercept-1) > 0.05 {
+			t.Errorf("%s: fit %.3f + %v x, expected 1 + [2 -3] x", loss, model.Intercept, model.Coef)
+		}
+	}
+
+	if _, err := NewLinearSVR(1, -1, EpsilonInsensitive, true); err == nil {
+		t.Errorf("expected error for negative epsilon")
+	}
+}
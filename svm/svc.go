@@ -0,0 +1,516 @@
+package svm
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/kernel"
+	"github.com/emef/go.ml/matrix"
+	"github.com/emef/go.ml/metrics"
+)
+
+const tau = 1e-12 // smallest curvature of an SMO step
+
+/*
+ kernel support vector classifier, solving the dual problem
+
+     min 1/2 a'Qa - sum_i a_i  s.t.  0 <= a_i <= C,  y'a = 0
+
+ with Q_ij = y_i y_j k(x_i, x_j), by Sequential Minimal Optimization
+ using second order working set selection (Fan et al., 2005, as in
+ libsvm). The decision function is
+
+     f(x) = sum_i a_i y_i k(x_i, x) + b
+
+ summed over the support vectors (a_i > 0). More than two classes are
+ handled one-vs-rest. With Probability set, a sigmoid is fit to
+ cross-validated decision values (Platt scaling) so PredictProba can
+ return class probabilities.
+*/
+type SVC struct {
+	C              float64       // inverse regularization strength
+	Kernel         kernel.Kernel // similarity between samples
+	Tol            float64       // largest KKT violation at convergence
+	MaxIter        int           // maximum number of SMO steps per binary problem
+	CacheSize      int           // kernel matrix rows kept in memory
+	Probability    bool          // whether to fit Platt scaling during Fit
+	Classes        []float64     // sorted distinct labels seen by Fit
+	Support        []int         // indices of the training support vectors
+	SupportVectors [][]float64   // the support vectors themselves
+	DualCoef       [][]float64   // a_i y_i, one row per binary problem, one column per support vector
+	Intercept      []float64     // intercept b of each binary problem
+	ProbA, ProbB   []float64     // sigmoid P(+1 | f) = 1 / (1 + exp(A f + B)) per binary problem
+	NIter          int           // most SMO steps used by any binary problem
+}
+
+/*
+ kernel SVM classifier constructor
+
+ arguments
+ ---------
+   C:      inverse regularization strength, larger fits the training
+           data more closely
+   kernel: kernel function, e.g. kernel.RBF(gamma)
+*/
+func NewSVC(C float64, k kernel.Kernel) (*SVC, error) {
+	if C <= 0 {
+		return nil, errors.New("C must be positive")
+	}
+	if k == nil {
+		return nil, errors.New("no kernel given")
+	}
+
+	model := new(SVC)
+	model.C = C
+	model.Kernel = k
+	model.Tol = 1e-3
+	model.MaxIter = 100000
+	model.CacheSize = 1000
+	return model, nil
+}
+
+// fit the model with samples (X) and class labels (y), which may be any floats
+func (model *SVC) Fit(X [][]float64, y []float64) error {
+	if err := datasets.CheckInput(X, y); err != nil {
+		return err
+	}
+
+	model.Classes = datasets.Classes(y)
+	if len(model.Classes) < 2 {
+		return errors.New("need at least two classes")
+	}
+
+	problems := binaryProblems(model.Classes, y)
+	cache := newKernelCache(model.Kernel, X, model.CacheSize)
+
+	alphas := make([][]float64, len(problems))
+	model.Intercept = make([]float64, len(problems))
+	model.NIter = 0
+	for k, sign := range problems {
+		alpha, b, iter, err := model.smo(cache, sign)
+		if err != nil {
+			return err
+		}
+		alphas[k], model.Intercept[k] = alpha, b
+		if iter > model.NIter {
+			model.NIter = iter
+		}
+	}
+
+	// support vectors of any binary problem, and their signed coefficients
+	model.Support = nil
+	for i := range X {
+		for k := range alphas {
+			if alphas[k][i] > 0 {
+				model.Support = append(model.Support, i)
+				break
+			}
+		}
+	}
+
+	model.SupportVectors = make([][]float64, len(model.Support))
+	model.DualCoef = make([][]float64, len(problems))
+	for k := range model.DualCoef {
+		model.DualCoef[k] = make([]float64, len(model.Support))
+	}
+	for s, i := range model.Support {
+		model.SupportVectors[s] = X[i]
+		for k := range problems {
+			model.DualCoef[k][s] = alphas[k][i] * problems[k][i]
+		}
+	}
+
+	model.ProbA, model.ProbB = nil, nil
+	if model.Probability {
+		return model.fitPlatt(X, y, problems)
+	}
+	return nil
+}
+
+/*
+ SMO for one binary problem with labels +/-1
+
+ returns
+ -------
+   (alpha, b, iterations, err)
+*/
+func (model *SVC) smo(cache *kernelCache, y []float64) ([]float64, float64, int, error) {
+	n := len(y)
+	C := model.C
+	alpha := make([]float64, n)
+
+	// gradient of the dual objective, Qa - 1
+	G := make([]float64, n)
+	for i := range G {
+		G[i] = -1
+	}
+
+	iter := 0
+	for ; iter < model.MaxIter; iter++ {
+		i, j := model.workingSet(cache, y, alpha, G)
+		if j == -1 {
+			break
+		}
+
+		Ki, Kj := cache.row(i), cache.row(j)
+		oldI, oldJ := alpha[i], alpha[j]
+
+		// solve the two variable subproblem analytically and clip to the box
+		quad := Ki[i] + Kj[j] - 2*Ki[j]
+		if quad <= 0 {
+			quad = tau
+		}
+		if y[i] != y[j] {
+			delta := (-G[i] - G[j]) / quad
+			diff := alpha[i] - alpha[j]
+			alpha[i] += delta
+			alpha[j] += delta
+			if diff > 0 && alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, diff
+			} else if diff <= 0 && alpha[i] < 0 {
+				alpha[i], alpha[j] = 0, -diff
+			}
+			if diff > 0 && alpha[i] > C {
+				alpha[i], alpha[j] = C, C-diff
+			} else if diff <= 0 && alpha[j] > C {
+				alpha[j], alpha[i] = C, C+diff
+			}
+		} else {
+			delta := (G[i] - G[j]) / quad
+			sum := alpha[i] + alpha[j]
+			alpha[i] -= delta
+			alpha[j] += delta
+			if sum > C && alpha[i] > C {
+				alpha[i], alpha[j] = C, sum-C
+			} else if sum <= C && alpha[j] < 0 {
+				alpha[j], alpha[i] = 0, sum
+			}
+			if sum > C && alpha[j] > C {
+				alpha[j], alpha[i] = C, sum-C
+			} else if sum <= C && alpha[i] < 0 {
+				alpha[i], alpha[j] = 0, sum
+			}
+		}
+
+		// G_t += Q_ti dAlpha_i + Q_tj dAlpha_j
+		dI, dJ := (alpha[i]-oldI)*y[i], (alpha[j]-oldJ)*y[j]
+		for t := range G {
+			G[t] += y[t] * (Ki[t]*dI + Kj[t]*dJ)
+		}
+	}
+
+	if iter == model.MaxIter {
+		return nil, 0, iter, errors.New("SMO did not converge")
+	}
+
+	// b from the free support vectors, or the middle of the feasible range
+	sum, free := 0.0, 0
+	upper, lower := math.Inf(1), math.Inf(-1)
+	for t := range alpha {
+		yG := y[t] * G[t]
+		switch {
+		case alpha[t] > 0 && alpha[t] < C:
+			sum += yG
+			free++
+		case (alpha[t] == 0) == (y[t] > 0):
+			upper = math.Min(upper, yG)
+		default:
+			lower = math.Max(lower, yG)
+		}
+	}
+	rho := (upper + lower) / 2
+	if free > 0 {
+		rho = sum / float64(free)
+	}
+
+	return alpha, -rho, iter, nil
+}
+
+/*
+ second order working set selection: i maximizes the violation -y_i G_i
+ among variables that can move up, j the decrease in the objective among
+ those that can move down
+
+ returns
+ -------
+   (i, j) or j = -1 once the largest violation is below Tol
+*/
+func (model *SVC) workingSet(cache *kernelCache, y, alpha, G []float64) (int, int) {
+	C := model.C
+
+	Gmax, i := math.Inf(-1), -1
+	for t := range alpha {
+		if (y[t] > 0 && alpha[t] < C) || (y[t] < 0 && alpha[t] > 0) {
+			if -y[t]*G[t] >= Gmax {
+				Gmax, i = -y[t]*G[t], t
+			}
+		}
+	}
+	if i == -1 {
+		return -1, -1
+	}
+
+	Ki := cache.row(i)
+	Gmin, j, best := math.Inf(1), -1, math.Inf(1)
+	for t := range alpha {
+		if (y[t] > 0 && alpha[t] > 0) || (y[t] < 0 && alpha[t] < C) {
+			v := -y[t] * G[t]
+			Gmin = math.Min(Gmin, v)
+
+			b := Gmax - v
+			if b > 0 {
+				a := cache.diag[i] + cache.diag[t] - 2*Ki[t]
+				if a <= 0 {
+					a = tau
+				}
+				if -b*b/a <= best {
+					best, j = -b*b/a, t
+				}
+			}
+		}
+	}
+
+	if Gmax-Gmin < model.Tol {
+		return i, -1
+	}
+	return i, j
+}
+
+// decision function value of each binary problem for samples (X)
+func (model SVC) DecisionFunction(X [][]float64) [][]float64 {
+	scores := make([][]float64, len(X))
+	k := make([]float64, len(model.SupportVectors))
+	for i := range X {
+		for s, sv := range model.SupportVectors {
+			k[s] = model.Kernel(sv, X[i])
+		}
+		scores[i] = make([]float64, len(model.DualCoef))
+		for p := range model.DualCoef {
+			f := model.Intercept[p]
+			for s, c := range model.DualCoef[p] {
+				f += c * k[s]
+			}
+			scores[i][p] = f
+		}
+	}
+	return scores
+}
+
+// predict class labels for samples (X)
+func (model SVC) Predict(X [][]float64) []float64 {
+	y := make([]float64, len(X))
+	for i, score := range model.DecisionFunction(X) {
+		if len(score) == 1 {
+			y[i] = model.Classes[0]
+			if score[0] > 0 {
+				y[i] = model.Classes[1]
+			}
+		} else {
+			y[i] = model.Classes[matrix.VecArgMax(score)]
+		}
+	}
+	return y
+}
+
+/*
+ predict the probability of each class (ordered as Classes) for samples
+ (X) from the Platt sigmoids; one-vs-rest probabilities are normalized
+ to sum to one. Returns nil unless the model was fit with Probability.
+*/
+func (model SVC) PredictProba(X [][]float64) [][]float64 {
+	if model.ProbA == nil {
+		return nil
+	}
+
+	proba := make([][]float64, len(X))
+	for i, score := range model.DecisionFunction(X) {
+		p := make([]float64, len(score))
+		for k, f := range score {
+			p[k] = plattProbability(f, model.ProbA[k], model.ProbB[k])
+		}
+
+		if len(p) == 1 {
+			proba[i] = []float64{1 - p[0], p[0]}
+			continue
+		}
+		total := 0.0
+		for _, v := range p {
+			total += v
+		}
+		for k := range p {
+			p[k] /= total
+		}
+		proba[i] = p
+	}
+	return proba
+}
+
+// accuracy of the predictions for X
+func (model SVC) Score(X [][]float64, y []float64) float64 {
+	return metrics.Accuracy(model.Predict(X), y)
+}
+
+// the +/-1 labels of each binary problem, a single one for two classes
+func binaryProblems(classes, y []float64) [][]float64 {
+	positive := classes[1:]
+	if len(classes) > 2 {
+		positive = classes
+	}
+
+	problems := make([][]float64, len(positive))
+	for k, class := range positive {
+		problems[k] = make([]float64, len(y))
+		for i := range y {
+			problems[k][i] = -1
+			if y[i] == class {
+				problems[k][i] = 1
+			}
+		}
+	}
+	return problems
+}
+
+/*
+ fits the Platt sigmoid of each binary problem to decision values from
+ 5-fold cross-validation, so the sigmoid isn't fit to the overconfident
+ values on the training data itself
+*/
+func (model *SVC) fitPlatt(X [][]float64, y []float64, problems [][]float64) error {
+	const folds = 5
+
+	n := len(X)
+	perm := rand.Perm(n)
+	decision := make([][]float64, n)
+
+	for fold := 0; fold < folds; fold++ {
+		var trainX, testX [][]float64
+		var trainY []float64
+		var test []int
+		for k, i := range perm {
+			if k%folds == fold {
+				testX = append(testX, X[i])
+				test = append(test, i)
+			} else {
+				trainX = append(trainX, X[i])
+				trainY = append(trainY, y[i])
+			}
+		}
+
+		sub := *model
+		sub.Probability = false
+		if len(datasets.Classes(trainY)) != len(model.Classes) {
+			// a class is missing from this fold, fall back to the full model
+			sub = *model
+		} else if err := sub.Fit(trainX, trainY); err != nil {
+			return err
+		}
+
+		for k, score := range sub.DecisionFunction(testX) {
+			decision[test[k]] = score
+		}
+	}
+
+	model.ProbA = make([]float64, len(problems))
+	model.ProbB = make([]float64, len(problems))
+	f := make([]float64, n)
+	for k := range problems {
+		for i := range f {
+			f[i] = decision[i][k]
+		}
+		model.ProbA[k], model.ProbB[k] = plattSigmoid(f, problems[k])
+	}
+
+	return nil
+}
+
+/*
+ fits P(+1 | f) = 1 / (1 + exp(A f + B)) to decision values f with labels
+ y by Newton's method with backtracking (Lin, Lin and Weng, 2007)
+
+ returns
+ -------
+   (A, B)
+*/
+func plattSigmoid(f, y []float64) (float64, float64) {
+	prior1, prior0 := 0.0, 0.0
+	for _, v := range y {
+		if v > 0 {
+			prior1++
+		} else {
+			prior0++
+		}
+	}
+
+	// regularized targets avoid overfitting separable data
+	hi, lo := (prior1+1)/(prior1+2), 1/(prior0+2)
+	t := make([]float64, len(y))
+	for i := range y {
+		t[i] = lo
+		if y[i] > 0 {
+			t[i] = hi
+		}
+	}
+
+	objective := func(A, B float64) float64 {
+		v := 0.0
+		for i := range f {
+			fApB := f[i]*A + B
+			if fApB >= 0 {
+				v += t[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				v += (t[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return v
+	}
+
+	A, B := 0.0, math.Log((prior0+1)/(prior1+1))
+	fval := objective(A, B)
+
+	for iter := 0; iter < 100; iter++ {
+		h11, h22, h21, g1, g2 := 1e-12, 1e-12, 0.0, 0.0, 0.0
+		for i := range f {
+			p := plattProbability(f[i], A, B)
+			d2 := p * (1 - p)
+			h11 += f[i] * f[i] * d2
+			h22 += d2
+			h21 += f[i] * d2
+			d1 := t[i] - p
+			g1 += f[i] * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+
+		step := 1.0
+		for ; step >= 1e-10; step /= 2 {
+			newA, newB := A+step*dA, B+step*dB
+			if newf := objective(newA, newB); newf < fval+1e-4*step*gd {
+				A, B, fval = newA, newB, newf
+				break
+			}
+		}
+		if step < 1e-10 {
+			break
+		}
+	}
+
+	return A, B
+}
+
+// 1 / (1 + exp(A f + B)) without overflow
+func plattProbability(f, A, B float64) float64 {
+	fApB := f*A + B
+	if fApB >= 0 {
+		return math.Exp(-fApB) / (1 + math.Exp(-fApB))
+	}
+	return 1 / (1 + math.Exp(fApB))
+}
@@ -0,0 +1,193 @@
+package svm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"github.com/emef/go.ml/datasets"
+	"github.com/emef/go.ml/kernel"
+)
+
+// label 1 inside the unit circle, 0 in a ring around it
+func circles(n int) ([][]float64, []float64) {
+	X := make([][]float64, n)
+	y := make([]float64, n)
+	for i := range X {
+		r, theta := 0.5*rand.Float64(), 2*math.Pi*rand.Float64()
+		if i%2 == 0 {
+			r, y[i] = 1.5+0.5*rand.Float64(), 0
+		} else {
+			y[i] = 1
+		}
+		X[i] = []float64{r * math.Cos(theta), r * math.Sin(theta)}
+	}
+	return X, y
+}
+
+func TestSVCNonlinear(t *testing.T) {
+	X, y := circles(200)
+
+	linear, _ := NewSVC(1, kernel.Linear())
+	linear.Fit(X, y)
+	if score := linear.Score(X, y); score > 0.8 {
+		t.Errorf("a linear kernel shouldn't separate circles, accuracy %.3f", score)
+	}
+
+	for name, k := range map[string]kernel.Kernel{
+		"rbf":        kernel.RBF(1),
+		"polynomial": kernel.Polynomial(2, 1, 1),
+	} {
+		model, _ := NewSVC(10, k)
+		if err := model.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if score := model.Score(X, y); score < 0.99 {
+			t.Errorf("%s kernel: training accuracy %.3f", name, score)
+		}
+
+		// the equality constraint y'a = 0 holds and the coefficients are boxed
+		sum := 0.0
+		for _, c := range model.DualCoef[0] {
+			sum += c
+			if math.Abs(c) > model.C+1e-9 || c == 0 {
+				t.Errorf("%s kernel: dual coefficient %.6f outside (0, C]", name, c)
+			}
+		}
+		if math.Abs(sum) > 1e-9 {
+			t.Errorf("%s kernel: dual coefficients sum to %.6f", name, sum)
+		}
+		if len(model.SupportVectors) == 0 || len(model.SupportVectors) > len(X)/2 {
+			t.Errorf("%s kernel: %d support vectors", name, len(model.SupportVectors))
+		}
+		for s, i := range model.Support {
+			if &model.SupportVectors[s][0] != &X[i][0] {
+				t.Errorf("support vector %d is not training sample %d", s, i)
+			}
+		}
+	}
+}
+
+// the separating line of a linear kernel SVC should be the one found by
+// dual coordinate descent on the same (unpenalized intercept aside) problem
+func TestSVCLinearKernel(t *testing.T) {
+	X, y := blobs(100, [][]float64{{1, 1}, {-1, -1}}, []float64{0, 1}, 0.8)
+
+	model, _ := NewSVC(1, kernel.Linear())
+	model.Fit(X, y)
+
+	// primal weights w = sum_i a_i y_i x_i
+	w := make([]float64, 2)
+	for s, sv := range model.SupportVectors {
+		w[0] += model.DualCoef[0][s] * sv[0]
+		w[1] += model.DualCoef[0][s] * sv[1]
+	}
+	for i, score := range model.DecisionFunction(X) {
+		f := w[0]*X[i][0] + w[1]*X[i][1] + model.Intercept[0]
+		if math.Abs(f-score[0]) > 1e-9 {
+			t.Fatalf("decision %.6f, primal %.6f", score[0], f)
+		}
+	}
+
+	svc, _ := NewLinearSVC(1, Hinge, true)
+	svc.Fit(X, y)
+	agree := 0
+	for i, yPred := range svc.Predict(X) {
+		if yPred == model.Predict(X[i : i+1])[0] {
+			agree++
+		}
+	}
+	if agree < 97 {
+		t.Errorf("kernel and linear SVC agree on %d of 100 samples", agree)
+	}
+}
+
+func TestSVCCache(t *testing.T) {
+	X, y := circles(100)
+
+	large, _ := NewSVC(1, kernel.RBF(0.5))
+	large.Fit(X, y)
+
+	// two cached rows is the minimum, and the solution must not change
+	small, _ := NewSVC(1, kernel.RBF(0.5))
+	small.CacheSize = 2
+	small.Fit(X, y)
+
+	if len(small.Support) != len(large.Support) {
+		t.Fatalf("%d support vectors with a small cache, %d with a large one",
+			len(small.Support), len(large.Support))
+	}
+	for s := range small.DualCoef[0] {
+		if math.Abs(small.DualCoef[0][s]-large.DualCoef[0][s]) > 1e-12 {
+			t.Errorf("dual coefficients differ with cache size")
+		}
+	}
+
+	cache := newKernelCache(kernel.Linear(), X, 3)
+	for _, i := range []int{0, 1, 2, 0, 3, 0, 1} {
+		cache.row(i)
+	}
+	// rows 0, 1, 2 miss, then 3 evicts 1 (0 was just used), so 1 misses again
+	if cache.misses != 5 || cache.order.Len() != 3 {
+		t.Errorf("%d misses and %d cached rows, expected 5 and 3", cache.misses, cache.order.Len())
+	}
+}
+
+func TestSVCProbability(t *testing.T) {
+	X, y := datasets.Load("cancer")
+	datasets.RandomShuffle(X, y)
+	standardize(X)
+
+	model, _ := NewSVC(1, kernel.RBF(0.03))
+	model.Probability = true
+	if err := model.Fit(X[:400], y[:400]); err != nil {
+		t.Fatal(err)
+	}
+
+	if acc := model.Score(X[400:], y[400:]); acc < 0.93 {
+		t.Errorf("held out accuracy %.3f", acc)
+	}
+
+	proba := model.PredictProba(X[400:])
+	logLoss := 0.0
+	for i, p := range proba {
+		if math.Abs(p[0]+p[1]-1) > 1e-12 || p[0] < 0 || p[1] < 0 {
+			t.Fatalf("invalid probabilities %v", p)
+		}
+		logLoss -= math.Log(math.Max(p[int(y[400+i])], 1e-15)) / float64(len(proba))
+	}
+	if logLoss > 0.3 {
+		t.Errorf("held out log loss %.3f", logLoss)
+	}
+}
+
+func TestSVCMulticlass(t *testing.T) {
+	centers := [][]float64{{0, 3}, {3, 0}, {-3, -3}}
+	X, y := blobs(150, centers, []float64{4, 5, 6}, 0.8)
+
+	model, _ := NewSVC(1, kernel.RBF(0.5))
+	model.Probability = true
+	model.Fit(X, y)
+
+	if len(model.DualCoef) != 3 {
+		t.Fatalf("expected one-vs-rest problems for 3 classes, got %d", len(model.DualCoef))
+	}
+	if score := model.Score(X, y); score < 0.97 {
+		t.Errorf("training accuracy %.3f", score)
+	}
+
+	// on average the true class should get most of the probability
+	truth := 0.0
+	for i, p := range model.PredictProba(X) {
+		if math.Abs(p[0]+p[1]+p[2]-1) > 1e-12 {
+			t.Fatalf("probabilities %v don't sum to 1", p)
+		}
+		truth += p[int(y[i])-4] / float64(len(X))
+	}
+	if truth < 0.8 {
+		t.Errorf("mean probability of the true class %.3f", truth)
+	}
+
+	if _, err := NewSVC(1, nil); err == nil {
+		t.Errorf("expected error without a kernel")
+	}
+}